@@ -0,0 +1,25 @@
+package beacon
+
+import "context"
+
+// BeaconEntry is a single randomness output from the beacon, analogous
+// to an unchained drand round: a round number and the (threshold)
+// signature over that round number that proves this round was produced
+// unbiasably.
+type BeaconEntry struct {
+	Round     uint64
+	Signature []byte
+}
+
+// BeaconAPI is satisfied by anything that can hand out verifiable,
+// unbiasable randomness rounds for leader election to consume.
+type BeaconAPI interface {
+	// Entry returns the beacon entry for round, blocking until it is
+	// available.
+	Entry(ctx context.Context, round uint64) (BeaconEntry, error)
+	// VerifyEntry checks that cur was legitimately produced following
+	// prev.
+	VerifyEntry(prev, cur BeaconEntry) error
+	// LatestRound returns the highest round the beacon has produced.
+	LatestRound() uint64
+}
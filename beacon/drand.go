@@ -0,0 +1,99 @@
+package beacon
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gitferry/zeitgeber/config"
+	"github.com/gitferry/zeitgeber/crypto"
+)
+
+// DrandBeacon polls a drand (https://drand.love) HTTP endpoint for
+// randomness rounds. The endpoint and the group's BLS public key are
+// read from config so a deployment can point at a public drand network
+// or a private threshold BLS group run for the experiment.
+type DrandBeacon struct {
+	client   *http.Client
+	endpoint string
+	groupPK  crypto.PublicKey
+
+	mu     sync.Mutex
+	latest uint64
+}
+
+// NewDrandBeacon creates a beacon that polls the drand endpoint
+// configured via config.GetConfig().DrandEndpoint() and verifies
+// rounds against config.GetConfig().DrandGroupPublicKey().
+func NewDrandBeacon() *DrandBeacon {
+	return &DrandBeacon{
+		client:   &http.Client{Timeout: 5 * time.Second},
+		endpoint: config.GetConfig().DrandEndpoint(),
+		groupPK:  crypto.PublicKey(config.GetConfig().DrandGroupPublicKey()),
+	}
+}
+
+type drandRound struct {
+	Round     uint64 `json:"round"`
+	Signature string `json:"signature"`
+}
+
+func (d *DrandBeacon) Entry(ctx context.Context, round uint64) (BeaconEntry, error) {
+	url := fmt.Sprintf("%s/public/%d", d.endpoint, round)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return BeaconEntry{}, fmt.Errorf("cannot build drand request: %w", err)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return BeaconEntry{}, fmt.Errorf("cannot reach drand endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+	var r drandRound
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return BeaconEntry{}, fmt.Errorf("cannot decode drand response: %w", err)
+	}
+	sig, err := hex.DecodeString(r.Signature)
+	if err != nil {
+		return BeaconEntry{}, fmt.Errorf("cannot decode drand signature for round %v: %w", r.Round, err)
+	}
+	d.mu.Lock()
+	if r.Round > d.latest {
+		d.latest = r.Round
+	}
+	d.mu.Unlock()
+	return BeaconEntry{Round: r.Round, Signature: sig}, nil
+}
+
+// VerifyEntry checks that cur directly follows prev and that its BLS
+// signature verifies against the drand group's public key, so a
+// replica cannot embed a forged entry to bias or predict leader
+// election.
+func (d *DrandBeacon) VerifyEntry(prev, cur BeaconEntry) error {
+	if cur.Round != prev.Round+1 {
+		return fmt.Errorf("non-consecutive beacon round: %v -> %v", prev.Round, cur.Round)
+	}
+	if err := crypto.VerifyAggregate(d.groupPK, roundMessage(cur.Round), cur.Signature); err != nil {
+		return fmt.Errorf("beacon signature does not verify for round %v: %w", cur.Round, err)
+	}
+	return nil
+}
+
+// roundMessage is the message an (unchained) drand round's signature
+// is produced over: the round number as an 8-byte big-endian integer.
+func roundMessage(round uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], round)
+	return buf[:]
+}
+
+func (d *DrandBeacon) LatestRound() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.latest
+}
@@ -6,14 +6,33 @@ import (
 
 	"github.com/gitferry/zeitgeber/config"
 	"github.com/gitferry/zeitgeber/crypto"
+	"github.com/gitferry/zeitgeber/identity"
 	"github.com/gitferry/zeitgeber/log"
 	"github.com/gitferry/zeitgeber/types"
 )
 
 type BlockChain struct {
+	n       int
 	highQC  *QC
+	highTC  *TC
 	forrest *LevelledForest
-	quorum  *Quorum
+	// quorums collects votes per Phase: hotstuff and twochain only ever
+	// populate quorums[Prepare], while tendermint's prevote/precommit
+	// locking needs both.
+	quorums map[Phase]*Quorum
+	// tcQuorums collects in-flight TMOs keyed by the view they report on
+	tcQuorums map[types.View]*tcQuorum
+	// signer produces this replica's partial signatures; groupPK
+	// verifies the threshold signatures aggregated from them. Defaults
+	// to a no-op signer so benchmarks can isolate consensus overhead.
+	signer  crypto.Signer
+	groupPK crypto.PublicKey
+	// nodePKs holds every participant's BLS public key share, keyed by
+	// NodeID. A block is signed by its single proposer rather than a
+	// quorum, so Block.Verify checks it against the proposer's own
+	// share instead of the aggregated groupPK that only a t-of-n
+	// combined signature can satisfy.
+	nodePKs map[identity.NodeID]crypto.PublicKey
 	// measurement
 	totalBlocks           int
 	committedBlocks       int
@@ -23,12 +42,59 @@ type BlockChain struct {
 
 func NewBlockchain(n int) *BlockChain {
 	bc := new(BlockChain)
+	bc.n = n
 	bc.forrest = NewLevelledForest()
-	bc.quorum = NewQuorum(n)
+	bc.quorums = make(map[Phase]*Quorum)
 	bc.highQC = &QC{View: 0}
+	bc.tcQuorums = make(map[types.View]*tcQuorum)
+	bc.signer = crypto.NoopSigner{}
 	return bc
 }
 
+// SetSigner installs the group public key and the Signer this replica
+// should use to produce and verify threshold signatures, replacing the
+// default no-op signer.
+func (bc *BlockChain) SetSigner(groupPK crypto.PublicKey, signer crypto.Signer) {
+	bc.groupPK = groupPK
+	bc.signer = signer
+}
+
+// SetNodePublicKeys installs every participant's BLS public key share,
+// used to verify the individual proposer signature on a block.
+func (bc *BlockChain) SetNodePublicKeys(pks map[identity.NodeID]crypto.PublicKey) {
+	bc.nodePKs = pks
+}
+
+// GroupPK returns the consensus group's public key, used to verify
+// aggregated threshold signatures on QCs.
+func (bc *BlockChain) GroupPK() crypto.PublicKey {
+	return bc.groupPK
+}
+
+// PubKeyFor returns proposer's BLS public key share, used to verify the
+// signature it placed on a block it proposed.
+func (bc *BlockChain) PubKeyFor(proposer identity.NodeID) crypto.PublicKey {
+	return bc.nodePKs[proposer]
+}
+
+// NodePublicKeys returns every participant's BLS public key share,
+// keyed by NodeID, used to verify the individual partial signatures
+// carried by a TC.
+func (bc *BlockChain) NodePublicKeys() map[identity.NodeID]crypto.PublicKey {
+	return bc.nodePKs
+}
+
+// QuorumSize returns the number of distinct replicas (2f+1, for
+// n = 3f+1) a BFT quorum needs.
+func (bc *BlockChain) QuorumSize() int {
+	return quorumSize(bc.n)
+}
+
+// Sign produces this replica's partial signature over msg.
+func (bc *BlockChain) Sign(msg []byte) crypto.Signature {
+	return bc.signer.Sign(msg)
+}
+
 func (bc *BlockChain) AddBlock(block *Block) {
 	blockContainer := &BlockContainer{block}
 	// TODO: add checks
@@ -42,9 +108,33 @@ func (bc *BlockChain) AddBlock(block *Block) {
 	bc.mu.Unlock()
 }
 
+// AddVote verifies vote's partial signature against its voter's own
+// BLS public key share before counting it towards quorum, and drops it
+// otherwise. Without this check, a single Byzantine replica submitting
+// an undecodable signature would make AggregateSignatures fail every
+// time GenerateQC ran for this blockID, permanently denying QC
+// formation for an otherwise-honest 2f+1 - a liveness break a BFT
+// quorum must tolerate.
 func (bc *BlockChain) AddVote(vote *Vote) (bool, *QC) {
-	bc.quorum.Add(vote)
-	return bc.GenerateQC(vote.View, vote.BlockID)
+	if err := crypto.VerifyPartial(bc.PubKeyFor(vote.Voter), VoteMessage(vote.View, vote.BlockID), vote.Signature); err != nil {
+		log.Warningf("dropping an invalid vote from %v, view: %v: %v", vote.Voter, vote.View, err)
+		return false, nil
+	}
+	bc.quorumForPhase(vote.Phase).Add(vote)
+	return bc.GenerateQC(vote.Phase, vote.View, vote.BlockID)
+}
+
+// quorumForPhase returns the quorum collecting votes for phase,
+// creating it on first use.
+func (bc *BlockChain) quorumForPhase(phase Phase) *Quorum {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	q, exists := bc.quorums[phase]
+	if !exists {
+		q = NewQuorum(bc.n)
+		bc.quorums[phase] = q
+	}
+	return q
 }
 
 func (bc *BlockChain) GetHighQC() *QC {
@@ -59,21 +149,26 @@ func (bc *BlockChain) UpdateHighQC(qc *QC) error {
 	return nil
 }
 
-func (bc *BlockChain) GenerateQC(view types.View, blockID crypto.Identifier) (bool, *QC) {
-	if !bc.quorum.SuperMajority(blockID) {
+func (bc *BlockChain) GenerateQC(phase Phase, view types.View, blockID crypto.Identifier) (bool, *QC) {
+	q := bc.quorumForPhase(phase)
+	if !q.SuperMajority(blockID) {
 		return false, nil
 	}
-	sigs, err := bc.quorum.GetSigs(blockID)
+	sigs, err := q.GetSigs(blockID)
 	if err != nil {
 		log.Warningf("cannot get signatures, %w", err)
 		return false, nil
 	}
+	aggSig, err := crypto.AggregateSignatures(sigs)
+	if err != nil {
+		log.Warningf("cannot aggregate signatures into a qc, view: %v, %w", view, err)
+		return false, nil
+	}
 	qc := &QC{
-		View:    view,
-		BlockID: blockID,
-		AggSig:  sigs,
-		// TODO: add real sig
-		Signature: nil,
+		View:      view,
+		BlockID:   blockID,
+		Phase:     phase,
+		Signature: aggSig,
 	}
 
 	err = bc.UpdateHighQC(qc)
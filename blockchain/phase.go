@@ -0,0 +1,35 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/gitferry/zeitgeber/crypto"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// Phase identifies which round a Vote or QC belongs to. HotStuff and
+// the two-chain variant only ever use Prepare; Tendermint's
+// prevote/precommit locking rule needs both, so quorums are collected
+// and certified per phase rather than per view alone.
+type Phase uint8
+
+const (
+	Prepare Phase = iota
+	Precommit
+)
+
+// NewVote builds an unsigned vote for blockID at view, in phase. The
+// caller is expected to fill in Voter and Signature before sending it.
+func NewVote(view types.View, blockID crypto.Identifier, phase Phase) *Vote {
+	return &Vote{View: view, BlockID: blockID, Phase: phase}
+}
+
+// GetBlock looks up a block by id, regardless of whether it has been
+// committed and pruned from the forest.
+func (bc *BlockChain) GetBlock(id crypto.Identifier) (*Block, error) {
+	vertex, exists := bc.forrest.GetVertex(id)
+	if !exists {
+		return nil, fmt.Errorf("the block does not exist, id: %x", id)
+	}
+	return vertex.GetBlock(), nil
+}
@@ -0,0 +1,69 @@
+package blockchain
+
+import "github.com/gitferry/zeitgeber/types"
+
+// Snapshot captures the committed-state summary a WAL compaction needs
+// to restore on replay: everything that can no longer be recovered by
+// re-running the (now-pruned) events that produced it. LastVotedView
+// and LockedQC are the active Safety implementation's state and
+// CurView is the pacemaker's current view; without them a replica that
+// compacted past a view it already voted or locked on could equivocate
+// after a crash. Tip is the highQC's block, carried along so the
+// restored forest has somewhere to root CommitBlock's parent walk;
+// without it a recovered replica's forest is empty while its blocks'
+// levels still reflect the true pre-crash height, and CommitBlock's
+// vertex.Level()-bc.forrest.LowestLevel arithmetic breaks.
+type Snapshot struct {
+	HighQC          *QC
+	Tip             *Block
+	TotalBlocks     int
+	CommittedBlocks int
+	LastVotedView   types.View
+	LockedQC        *QC
+	CurView         types.View
+}
+
+// MakeSnapshot captures bc's current committed-state summary, along
+// with the caller's Safety and Pacemaker state, for a WAL compaction.
+func (bc *BlockChain) MakeSnapshot(lastVotedView types.View, lockedQC *QC, curView types.View) Snapshot {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	var tip *Block
+	if vertex, exists := bc.forrest.GetVertex(bc.highQC.BlockID); exists {
+		tip = vertex.GetBlock()
+	}
+	return Snapshot{
+		HighQC:          bc.highQC,
+		Tip:             tip,
+		TotalBlocks:     bc.totalBlocks,
+		CommittedBlocks: bc.committedBlocks,
+		LastVotedView:   lastVotedView,
+		LockedQC:        lockedQC,
+		CurView:         curView,
+	}
+}
+
+// RestoreSnapshot restores a previously captured Snapshot's
+// blockchain-owned fields, used when replaying a WAL whose older
+// segments have already been compacted away. s.LastVotedView,
+// s.LockedQC and s.CurView are Safety- and Pacemaker-owned; the caller
+// is responsible for restoring those directly.
+//
+// s.Tip, if present, is reinserted as a root of the (freshly
+// constructed, empty) forest at its own level, mirroring the
+// &BlockContainer{block}/AddVertex pattern AddBlock uses for a live
+// block. LowestLevel is raised to that same level so a subsequent
+// CommitBlock's parent walk counts from the restored height rather
+// than from a fresh forest's zero value.
+func (bc *BlockChain) RestoreSnapshot(s Snapshot) error {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.highQC = s.HighQC
+	bc.totalBlocks = s.TotalBlocks
+	bc.committedBlocks = s.CommittedBlocks
+	if s.Tip != nil {
+		bc.forrest.AddVertex(&BlockContainer{s.Tip})
+		bc.forrest.LowestLevel = uint64(s.Tip.View)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/gitferry/zeitgeber/crypto"
+	"github.com/gitferry/zeitgeber/identity"
+	"github.com/gitferry/zeitgeber/log"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// TC is a timeout certificate: proof that a supermajority of replicas
+// gave up on View without committing. It carries the highest QC any of
+// the timed-out replicas had seen so the next leader can propose
+// extending it instead of the (possibly stale) QC it holds locally.
+// Sigs holds each contributing replica's own partial signature over
+// TCMessage(View); unlike a QC's Signature, these are verified
+// individually rather than threshold-aggregated, since a TC only needs
+// to prove how many distinct replicas timed out, not combine them into
+// one.
+type TC struct {
+	View   types.View
+	HighQC *QC
+	Sigs   []crypto.Signature
+}
+
+// TCMessage is the exact byte string a timeout report's partial
+// signature is computed over: H(tcDomain||view). The domain tag keeps
+// a TMO signature from being replayed as a vote or block signature for
+// the same view.
+func TCMessage(view types.View) []byte {
+	buf := make([]byte, 9)
+	buf[0] = tcDomain
+	binary.BigEndian.PutUint64(buf[1:], uint64(view))
+	return buf
+}
+
+// Verify checks that tc carries at least quorumSize valid partial
+// signatures from distinct, known replicas over TCMessage(tc.View),
+// each checked against its signer's own BLS public key share - the TC
+// counterpart of QC.Verify. Without this, a single replica could
+// fabricate a TC for an arbitrary view and force every honest replica
+// to jump to it and adopt an arbitrary HighQC.
+func (tc *TC) Verify(nodePKs map[identity.NodeID]crypto.PublicKey, quorumSize int) error {
+	msg := TCMessage(tc.View)
+	signers := make(map[identity.NodeID]bool, len(tc.Sigs))
+	for _, sig := range tc.Sigs {
+		idx, err := crypto.SignerIndex(sig)
+		if err != nil {
+			return fmt.Errorf("tc carries an undecodable signature, view: %v: %w", tc.View, err)
+		}
+		nodeID := identity.NodeID(idx)
+		pk, ok := nodePKs[nodeID]
+		if !ok {
+			return fmt.Errorf("tc carries a signature from an unknown node %v, view: %v", nodeID, tc.View)
+		}
+		if err := crypto.VerifyPartial(pk, msg, sig); err != nil {
+			return fmt.Errorf("tc carries an invalid signature from node %v, view: %v: %w", nodeID, tc.View, err)
+		}
+		signers[nodeID] = true
+	}
+	if len(signers) < quorumSize {
+		return fmt.Errorf("tc has only %d valid signatures, need %d, view: %v", len(signers), quorumSize, tc.View)
+	}
+	return nil
+}
+
+// tcQuorum collects verified TMO signatures for a single view,
+// mirroring Quorum's role for votes, and tracks the highest QC
+// reported by any of them.
+type tcQuorum struct {
+	n    int
+	mu   sync.Mutex
+	sigs map[identity.NodeID]crypto.Signature
+	high *QC
+}
+
+func newTCQuorum(n int) *tcQuorum {
+	return &tcQuorum{
+		n:    n,
+		sigs: make(map[identity.NodeID]crypto.Signature),
+	}
+}
+
+func (q *tcQuorum) add(nodeID identity.NodeID, highQC *QC, sig crypto.Signature) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.sigs[nodeID] = sig
+	if q.high == nil || highQC.View > q.high.View {
+		q.high = highQC
+	}
+}
+
+// superMajority reports whether 2f+1 distinct replicas (n = 3f+1) have
+// timed out on this view.
+func (q *tcQuorum) superMajority() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.sigs) >= quorumSize(q.n)
+}
+
+// sigSlice returns every signature collected so far, to embed in the
+// TC once a supermajority has formed.
+func (q *tcQuorum) sigSlice() []crypto.Signature {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sigs := make([]crypto.Signature, 0, len(q.sigs))
+	for _, sig := range q.sigs {
+		sigs = append(sigs, sig)
+	}
+	return sigs
+}
+
+// quorumSize returns the number of distinct replicas (2f+1, for
+// n = 3f+1) a BFT quorum needs.
+func quorumSize(n int) int {
+	return n - (n-1)/3
+}
+
+// AddTMO verifies sig against nodeID's own BLS public key share and, if
+// valid, records the timeout report for view along with the highest QC
+// that replica had observed, returning the resulting TC once a
+// supermajority of replicas have timed out on the same view. A replica
+// submitting an invalid signature is dropped rather than counted, so
+// one Byzantine TMO can't poison quorum formation for the whole view.
+func (bc *BlockChain) AddTMO(view types.View, nodeID identity.NodeID, highQC *QC, sig crypto.Signature) (bool, *TC) {
+	if err := crypto.VerifyPartial(bc.PubKeyFor(nodeID), TCMessage(view), sig); err != nil {
+		log.Warningf("dropping an invalid tmo from %v, view: %v: %v", nodeID, view, err)
+		return false, nil
+	}
+
+	bc.mu.Lock()
+	tcq, exists := bc.tcQuorums[view]
+	if !exists {
+		tcq = newTCQuorum(bc.n)
+		bc.tcQuorums[view] = tcq
+	}
+	bc.mu.Unlock()
+
+	tcq.add(nodeID, highQC, sig)
+	if !tcq.superMajority() {
+		return false, nil
+	}
+
+	tc := &TC{View: view, HighQC: tcq.high, Sigs: tcq.sigSlice()}
+	bc.mu.Lock()
+	bc.highTC = tc
+	delete(bc.tcQuorums, view)
+	bc.mu.Unlock()
+	return true, tc
+}
+
+// GetHighTC returns the most recently formed TC, or nil if none has
+// formed yet.
+func (bc *BlockChain) GetHighTC() *TC {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	return bc.highTC
+}
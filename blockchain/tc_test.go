@@ -0,0 +1,94 @@
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/gitferry/zeitgeber/crypto"
+	"github.com/gitferry/zeitgeber/identity"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+func newTestBlockchain(t *testing.T, n int) (*BlockChain, []crypto.PrivateKeyShare) {
+	t.Helper()
+	_, shares := crypto.KeyGen(n, n-(n-1)/3, []byte("tc-test-seed"))
+	bc := NewBlockchain(n)
+	nodePKs := make(map[identity.NodeID]crypto.PublicKey, n)
+	for i, share := range shares {
+		nodePKs[identity.NodeID(i+1)] = share.PublicKey
+	}
+	bc.SetNodePublicKeys(nodePKs)
+	return bc, shares
+}
+
+func tmoSig(shares []crypto.PrivateKeyShare, nodeID identity.NodeID, view types.View) crypto.Signature {
+	return crypto.Sign(shares[nodeID-1], TCMessage(view))
+}
+
+func TestAddTMOFormsTCAtQuorum(t *testing.T) {
+	const n = 4
+	bc, shares := newTestBlockchain(t, n)
+	view := types.View(5)
+	highQC := &QC{View: 1}
+
+	// n = 3f+1 = 4, so quorum is 2f+1 = 3: the first two TMOs must not
+	// form a TC yet.
+	for _, id := range []identity.NodeID{1, 2} {
+		isBuilt, tc := bc.AddTMO(view, id, highQC, tmoSig(shares, id, view))
+		if isBuilt {
+			t.Fatalf("tc formed early with only %v timeouts", id)
+		}
+		if tc != nil {
+			t.Fatalf("expected a nil tc before quorum, got %+v", tc)
+		}
+	}
+
+	isBuilt, tc := bc.AddTMO(view, 3, highQC, tmoSig(shares, 3, view))
+	if !isBuilt || tc == nil {
+		t.Fatalf("tc did not form once 2f+1 replicas timed out")
+	}
+	if tc.View != view {
+		t.Fatalf("tc.View = %v, want %v", tc.View, view)
+	}
+	if len(tc.Sigs) != bc.QuorumSize() {
+		t.Fatalf("tc carries %d signatures, want %d", len(tc.Sigs), bc.QuorumSize())
+	}
+	if err := tc.Verify(bc.NodePublicKeys(), bc.QuorumSize()); err != nil {
+		t.Fatalf("tc failed verification: %v", err)
+	}
+}
+
+func TestAddTMODropsInvalidSignatureWithoutBlockingQuorum(t *testing.T) {
+	const n = 4
+	bc, shares := newTestBlockchain(t, n)
+	view := types.View(7)
+	highQC := &QC{View: 2}
+
+	// node 4 submits garbage instead of a real partial signature; it
+	// must be dropped rather than poisoning the view's quorum.
+	isBuilt, tc := bc.AddTMO(view, 4, highQC, crypto.Signature("not-a-signature"))
+	if isBuilt || tc != nil {
+		t.Fatalf("an invalid tmo was counted towards the quorum")
+	}
+
+	for _, id := range []identity.NodeID{1, 2} {
+		if isBuilt, _ := bc.AddTMO(view, id, highQC, tmoSig(shares, id, view)); isBuilt {
+			t.Fatalf("tc formed early with only %v timeouts", id)
+		}
+	}
+	isBuilt, tc = bc.AddTMO(view, 3, highQC, tmoSig(shares, 3, view))
+	if !isBuilt || tc == nil {
+		t.Fatalf("the honest 2f+1 replicas could not form a tc after a byzantine tmo")
+	}
+	if err := tc.Verify(bc.NodePublicKeys(), bc.QuorumSize()); err != nil {
+		t.Fatalf("tc failed verification: %v", err)
+	}
+}
+
+func TestTCVerifyRejectsAForgedTC(t *testing.T) {
+	const n = 4
+	bc, _ := newTestBlockchain(t, n)
+	forged := &TC{View: 9, HighQC: &QC{View: 1}}
+	if err := forged.Verify(bc.NodePublicKeys(), bc.QuorumSize()); err == nil {
+		t.Fatal("a tc with no signatures verified successfully")
+	}
+}
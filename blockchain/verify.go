@@ -0,0 +1,60 @@
+package blockchain
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gitferry/zeitgeber/crypto"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// Domain tags distinguish the byte strings different kinds of messages
+// are signed over, so a signature produced for one can never verify as
+// a signature for another over the same (view, blockID) - e.g. a vote
+// a replica broadcasts can't be replayed as that replica's signature
+// on a same-view, same-block proposal.
+const (
+	voteDomain  byte = 1
+	blockDomain byte = 2
+	tcDomain    byte = 3
+)
+
+// VoteMessage is the exact byte string a vote (and therefore a QC's
+// aggregated signature) is computed over: H(voteDomain||view||blockID).
+func VoteMessage(view types.View, blockID crypto.Identifier) []byte {
+	return signedMessage(voteDomain, view, blockID)
+}
+
+// BlockMessage is the exact byte string a proposer signs over its
+// block: H(blockDomain||view||blockID).
+func BlockMessage(view types.View, blockID crypto.Identifier) []byte {
+	return signedMessage(blockDomain, view, blockID)
+}
+
+func signedMessage(domain byte, view types.View, blockID crypto.Identifier) []byte {
+	buf := make([]byte, 9, 9+len(blockID))
+	buf[0] = domain
+	binary.BigEndian.PutUint64(buf[1:9], uint64(view))
+	buf = append(buf, blockID[:]...)
+	return buf
+}
+
+// Verify checks the QC's threshold signature against the consensus
+// group's public key.
+func (qc *QC) Verify(groupPK crypto.PublicKey) error {
+	if err := crypto.VerifyAggregate(groupPK, VoteMessage(qc.View, qc.BlockID), qc.Signature); err != nil {
+		return fmt.Errorf("qc failed signature verification, view: %v: %w", qc.View, err)
+	}
+	return nil
+}
+
+// Verify checks the proposer's signature on the block against the
+// proposer's own BLS public key share. Unlike a QC, a block is signed
+// by its single proposer rather than a quorum, so it is checked as a
+// partial signature rather than a threshold-aggregated one.
+func (b *Block) Verify(proposerPK crypto.PublicKey) error {
+	if err := crypto.VerifyPartial(proposerPK, BlockMessage(b.View, b.ID), b.Signature); err != nil {
+		return fmt.Errorf("block failed signature verification, view: %v: %w", b.View, err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// Signature is an opaque BLS signature: either a single participant's
+// compressed G1 partial signature (prefixed with the index that
+// produced it, so AggregateSignatures knows how to weight it) or the
+// constant-size compressed G1 point produced by Lagrange-combining t
+// of them into a threshold signature.
+type Signature []byte
+
+// PublicKey is a compressed BLS12-381 G2 point: either the group
+// public key produced by KeyGen, which verifies a threshold-aggregated
+// Signature, or a single participant's public key share, which
+// verifies that participant's own partial Signature.
+type PublicKey []byte
+
+// PrivateKeyShare is one participant's share of the threshold private
+// key, produced by KeyGen alongside the group PublicKey. PublicKey is
+// the (non-secret) commitment g2^Share, published so peers can verify
+// this participant's partial signatures without learning Share itself.
+type PrivateKeyShare struct {
+	Index     int
+	Share     []byte
+	PublicKey PublicKey
+}
+
+// frOrder is the order r of the BLS12-381 scalar field Fr.
+var frOrder, _ = new(big.Int).SetString(
+	"73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// sigDomain is the hash-to-curve domain separation tag signatures are
+// produced and checked under.
+var sigDomain = []byte("BAMBOO-BLS-SIG-BLS12381G1_XMD:SHA-256_SSWU_RO_")
+
+// KeyGen runs a trusted-dealer BLS12-381 threshold keygen for n
+// participants with threshold t: any t of the n resulting partial
+// signatures Lagrange-combine into a signature that verifies against
+// the single group PublicKey.
+//
+// The dealer's secret polynomial is derived from seed rather than
+// fresh per-participant randomness, so that every replica process -
+// each of which calls KeyGen independently, there being no live
+// key-distribution round trip in this harness - reconstructs identical
+// share material without coordination. seed must therefore be an
+// actual secret every replica's deployment shares out of band (e.g. a
+// config-distributed value, the same way the drand group public key
+// is), never derived from n, t or any other value an outside observer
+// can see: anyone who can compute the dealer polynomial can compute
+// every replica's private key share and forge its partial signatures.
+func KeyGen(n, t int, seed []byte) (PublicKey, []PrivateKeyShare) {
+	coeffs := make([]*big.Int, t)
+	for i := 0; i < t; i++ {
+		coeffs[i] = dealerCoefficient(seed, i)
+	}
+
+	g2 := bls12381.NewG2()
+	groupPK := g2.ToCompressed(g2.MulScalarBig(g2.New(), g2.One(), coeffs[0]))
+
+	shares := make([]PrivateKeyShare, n)
+	for i := 0; i < n; i++ {
+		scalar := evalPoly(coeffs, int64(i+1))
+		pk := g2.ToCompressed(g2.MulScalarBig(g2.New(), g2.One(), scalar))
+		shares[i] = PrivateKeyShare{
+			Index:     i + 1,
+			Share:     scalar.Bytes(),
+			PublicKey: PublicKey(pk),
+		}
+	}
+	return PublicKey(groupPK), shares
+}
+
+// dealerCoefficient derives the i'th coefficient of the dealer's degree
+// t-1 polynomial from seed and i; see KeyGen.
+func dealerCoefficient(seed []byte, i int) *big.Int {
+	h := sha256.New()
+	h.Write([]byte("bamboo-bls-trusted-dealer"))
+	h.Write(seed)
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(i))
+	h.Write(buf[:])
+	return new(big.Int).Mod(new(big.Int).SetBytes(h.Sum(nil)), frOrder)
+}
+
+// evalPoly evaluates the polynomial with the given coefficients
+// (lowest degree first) at x, modulo the scalar field order.
+func evalPoly(coeffs []*big.Int, x int64) *big.Int {
+	result := new(big.Int)
+	xBig := big.NewInt(x)
+	pow := big.NewInt(1)
+	for _, c := range coeffs {
+		result.Add(result, new(big.Int).Mul(c, pow))
+		result.Mod(result, frOrder)
+		pow.Mul(pow, xBig)
+		pow.Mod(pow, frOrder)
+	}
+	return result
+}
+
+// Sign produces share's partial signature over msg: H(msg) scaled by
+// share's secret scalar, compressed and tagged with share's index so
+// AggregateSignatures knows how to Lagrange-weight it.
+func Sign(share PrivateKeyShare, msg []byte) Signature {
+	g1 := bls12381.NewG1()
+	h, err := g1.HashToCurve(msg, sigDomain)
+	if err != nil {
+		panic(fmt.Sprintf("cannot hash message onto G1: %v", err))
+	}
+	point := g1.MulScalarBig(g1.New(), h, new(big.Int).SetBytes(share.Share))
+	return encodePartial(share.Index, g1.ToCompressed(point))
+}
+
+// VerifyPartial checks a participant's partial signature over msg
+// against that participant's own public key share.
+func VerifyPartial(pubKeyShare PublicKey, msg []byte, sig Signature) error {
+	_, point, err := decodePartial(sig)
+	if err != nil {
+		return err
+	}
+	pk, err := bls12381.NewG2().FromCompressed(pubKeyShare)
+	if err != nil {
+		return fmt.Errorf("cannot decode public key share: %w", err)
+	}
+	return verify(point, pk, msg)
+}
+
+// AggregateSignatures Lagrange-combines t-of-n partial signatures into
+// the single constant-size threshold signature that becomes QC.Signature.
+func AggregateSignatures(sigs []Signature) (Signature, error) {
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("cannot aggregate zero signatures")
+	}
+	indices := make([]int, 0, len(sigs))
+	points := make(map[int]*bls12381.PointG1, len(sigs))
+	for _, s := range sigs {
+		idx, point, err := decodePartial(s)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := points[idx]; dup {
+			return nil, fmt.Errorf("duplicate partial signature from participant %d", idx)
+		}
+		indices = append(indices, idx)
+		points[idx] = point
+	}
+
+	g1 := bls12381.NewG1()
+	agg := g1.Zero()
+	for _, idx := range indices {
+		weighted := g1.MulScalarBig(g1.New(), points[idx], lagrangeCoefficient(indices, idx))
+		agg = g1.Add(g1.New(), agg, weighted)
+	}
+	return Signature(g1.ToCompressed(agg)), nil
+}
+
+// VerifyAggregate checks a constant-size threshold signature over msg
+// against the group public key.
+func VerifyAggregate(groupPK PublicKey, msg []byte, sig Signature) error {
+	point, err := bls12381.NewG1().FromCompressed(sig)
+	if err != nil {
+		return fmt.Errorf("cannot decode aggregate signature: %w", err)
+	}
+	pk, err := bls12381.NewG2().FromCompressed(groupPK)
+	if err != nil {
+		return fmt.Errorf("cannot decode group public key: %w", err)
+	}
+	return verify(point, pk, msg)
+}
+
+// verify checks sig == H(msg)^sk for the secret key behind pk, via the
+// pairing equality e(sig, g2) == e(H(msg), pk).
+func verify(sig *bls12381.PointG1, pk *bls12381.PointG2, msg []byte) error {
+	g1 := bls12381.NewG1()
+	h, err := g1.HashToCurve(msg, sigDomain)
+	if err != nil {
+		panic(fmt.Sprintf("cannot hash message onto G1: %v", err))
+	}
+	engine := bls12381.NewEngine()
+	engine.AddPair(sig, bls12381.NewG2().One())
+	engine.AddPairInv(h, pk)
+	if !engine.Check() {
+		return fmt.Errorf("signature does not verify against the given public key")
+	}
+	return nil
+}
+
+// lagrangeCoefficient computes the Lagrange basis coefficient for
+// index i, evaluated at x=0, over the participant set indices. Used to
+// reconstruct the dealer's secret-weighted sum from any t-or-more of
+// the shares it produced, without ever reconstructing the secret
+// itself.
+func lagrangeCoefficient(indices []int, i int) *big.Int {
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+	for _, j := range indices {
+		if j == i {
+			continue
+		}
+		num.Mod(num.Mul(num, big.NewInt(int64(-j))), frOrder)
+		diff := new(big.Int).Sub(big.NewInt(int64(i)), big.NewInt(int64(j)))
+		den.Mod(den.Mul(den, diff), frOrder)
+	}
+	return num.Mod(num.Mul(num, new(big.Int).ModInverse(den, frOrder)), frOrder)
+}
+
+// encodePartial prefixes a compressed G1 point with the 4-byte
+// big-endian participant index that produced it.
+func encodePartial(idx int, point []byte) Signature {
+	out := make([]byte, 4+len(point))
+	binary.BigEndian.PutUint32(out, uint32(idx))
+	copy(out[4:], point)
+	return out
+}
+
+// SignerIndex returns the participant index embedded in a partial
+// Signature, without decoding the G1 point itself - used to look up
+// which node's public key a partial signature (e.g. one carried by a
+// TC) should be checked against.
+func SignerIndex(sig Signature) (int, error) {
+	if len(sig) <= 4 {
+		return 0, fmt.Errorf("partial signature too short")
+	}
+	return int(binary.BigEndian.Uint32(sig[:4])), nil
+}
+
+// decodePartial splits a partial Signature back into its participant
+// index and G1 point.
+func decodePartial(sig Signature) (int, *bls12381.PointG1, error) {
+	if len(sig) <= 4 {
+		return 0, nil, fmt.Errorf("partial signature too short")
+	}
+	idx := int(binary.BigEndian.Uint32(sig[:4]))
+	point, err := bls12381.NewG1().FromCompressed(sig[4:])
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot decode partial signature from participant %d: %w", idx, err)
+	}
+	return idx, point, nil
+}
@@ -0,0 +1,52 @@
+package crypto
+
+import "testing"
+
+func TestBLSThresholdSignRoundtrip(t *testing.T) {
+	seed := []byte("test-seed")
+	groupPK, shares := KeyGen(4, 3, seed)
+
+	msg := []byte("hello bamboo")
+	sigs := make([]Signature, 0, len(shares))
+	for _, share := range shares {
+		sig := Sign(share, msg)
+		if err := VerifyPartial(share.PublicKey, msg, sig); err != nil {
+			t.Fatalf("partial signature from participant %d failed to verify: %v", share.Index, err)
+		}
+		sigs = append(sigs, sig)
+	}
+
+	agg, err := AggregateSignatures(sigs[:3])
+	if err != nil {
+		t.Fatalf("cannot aggregate partial signatures: %v", err)
+	}
+	if err := VerifyAggregate(groupPK, msg, agg); err != nil {
+		t.Fatalf("aggregate signature failed to verify: %v", err)
+	}
+
+	if err := VerifyAggregate(groupPK, []byte("a different message"), agg); err == nil {
+		t.Fatal("aggregate signature verified against the wrong message")
+	}
+}
+
+func TestKeyGenRequiresTheSeed(t *testing.T) {
+	groupPK1, _ := KeyGen(4, 3, []byte("seed-one"))
+	groupPK2, _ := KeyGen(4, 3, []byte("seed-two"))
+
+	if string(groupPK1) == string(groupPK2) {
+		t.Fatal("different seeds produced the same group public key")
+	}
+}
+
+func TestSignerIndexMatchesEncodedPartial(t *testing.T) {
+	_, shares := KeyGen(4, 3, []byte("another-seed"))
+	sig := Sign(shares[1], []byte("msg"))
+
+	idx, err := SignerIndex(sig)
+	if err != nil {
+		t.Fatalf("cannot read signer index: %v", err)
+	}
+	if idx != shares[1].Index {
+		t.Fatalf("signer index = %d, want %d", idx, shares[1].Index)
+	}
+}
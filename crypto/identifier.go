@@ -0,0 +1,5 @@
+package crypto
+
+// Identifier is a content hash identifying a block: a fixed-size
+// SHA-256 digest.
+type Identifier [32]byte
@@ -0,0 +1,35 @@
+package crypto
+
+// Signer abstracts over signing and verification so the consensus path
+// can fall back to a no-op signer for benchmarks that want to isolate
+// pure consensus overhead from cryptographic cost.
+type Signer interface {
+	Sign(msg []byte) Signature
+	Verify(pk PublicKey, msg []byte, sig Signature) error
+}
+
+type blsSigner struct {
+	share PrivateKeyShare
+}
+
+// NewBLSSigner wraps share as a Signer that produces real BLS12-381
+// partial signatures.
+func NewBLSSigner(share PrivateKeyShare) Signer {
+	return &blsSigner{share: share}
+}
+
+func (s *blsSigner) Sign(msg []byte) Signature {
+	return Sign(s.share, msg)
+}
+
+func (s *blsSigner) Verify(pk PublicKey, msg []byte, sig Signature) error {
+	return VerifyAggregate(pk, msg, sig)
+}
+
+// NoopSigner never signs or verifies. It exists for benchmarks that
+// want to measure consensus latency without cryptographic overhead.
+type NoopSigner struct{}
+
+func (NoopSigner) Sign(msg []byte) Signature { return nil }
+
+func (NoopSigner) Verify(pk PublicKey, msg []byte, sig Signature) error { return nil }
@@ -0,0 +1,82 @@
+package election
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/gitferry/zeitgeber/beacon"
+	"github.com/gitferry/zeitgeber/identity"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// BeaconElection picks the leader for a view from an unbiasable
+// randomness beacon instead of deterministic rotation, so an adaptive
+// adversary cannot grind ahead to know, or bias, who will lead a future
+// view.
+type BeaconElection struct {
+	beacon beacon.BeaconAPI
+	n      int
+}
+
+// NewBeaconElection creates a beacon-driven leader election over n
+// replicas identified 1..n.
+func NewBeaconElection(api beacon.BeaconAPI, n int) *BeaconElection {
+	return &BeaconElection{beacon: api, n: n}
+}
+
+// IsLeader reports whether id is the leader for view.
+func (e *BeaconElection) IsLeader(id identity.NodeID, view types.View) bool {
+	return e.FindLeaderFor(view) == id
+}
+
+// FindLeaderFor derives the leader for view as (H(beacon_round(view) ||
+// view) mod n) + 1. Each view consumes the beacon round of the same number,
+// keeping leader assignment in lockstep with the randomness source.
+func (e *BeaconElection) FindLeaderFor(view types.View) identity.NodeID {
+	entry, err := e.beacon.Entry(context.Background(), uint64(view))
+	if err != nil {
+		// the beacon is unreachable: fall back to a deterministic
+		// rotation rather than stall leader election entirely
+		return identity.NodeID(uint64(view)%uint64(e.n) + 1)
+	}
+	return leaderFromEntry(entry, view, e.n)
+}
+
+// Entry returns the beacon entry a leader proposing for view must embed
+// in its block.
+func (e *BeaconElection) Entry(view types.View) (beacon.BeaconEntry, error) {
+	return e.beacon.Entry(context.Background(), uint64(view))
+}
+
+// VerifyProposal checks that entry, the beacon entry a block proposed
+// for view embeds, legitimately follows the entry for view-1, before
+// trusting Election.IsLeader's verdict on the proposer. It fetches the
+// preceding entry fresh rather than from a cache, since a single
+// replica-wide "last queried" entry would be clobbered by unrelated
+// FindLeaderFor/Entry calls for other views (e.g. the next view's vote
+// aggregator lookup) made between this replica casting its own vote
+// and the next proposal arriving.
+func (e *BeaconElection) VerifyProposal(view types.View, entry beacon.BeaconEntry) error {
+	prev, err := e.beacon.Entry(context.Background(), uint64(view-1))
+	if err != nil {
+		return fmt.Errorf("cannot fetch beacon entry for view %v: %w", view-1, err)
+	}
+	if err := e.beacon.VerifyEntry(prev, entry); err != nil {
+		return fmt.Errorf("invalid beacon entry: %w", err)
+	}
+	return nil
+}
+
+func leaderFromEntry(entry beacon.BeaconEntry, view types.View, n int) identity.NodeID {
+	h := sha256.New()
+	h.Write(entry.Signature)
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(view))
+	h.Write(buf[:])
+	digest := h.Sum(nil)
+	idx := new(big.Int).Mod(new(big.Int).SetBytes(digest), big.NewInt(int64(n))).Int64()
+	return identity.NodeID(idx + 1)
+}
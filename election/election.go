@@ -0,0 +1,15 @@
+package election
+
+import (
+	"github.com/gitferry/zeitgeber/identity"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// Election picks the leader for a view. BeaconElection derives it from
+// an unbiasable randomness beacon; Rotation derives it from a fixed,
+// deterministic round-robin order instead, for runs that don't need -
+// or can't rely on - a live beacon.
+type Election interface {
+	IsLeader(id identity.NodeID, view types.View) bool
+	FindLeaderFor(view types.View) identity.NodeID
+}
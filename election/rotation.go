@@ -0,0 +1,30 @@
+package election
+
+import (
+	"github.com/gitferry/zeitgeber/identity"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// Rotation picks the leader for a view by deterministic round-robin
+// over n replicas identified 1..n. It carries no randomness source and
+// so no protection against an adaptive adversary predicting or biasing
+// a future leader; see BeaconElection for that.
+type Rotation struct {
+	n int
+}
+
+// NewRotation creates a deterministic round-robin leader election over
+// n replicas identified 1..n.
+func NewRotation(n int) *Rotation {
+	return &Rotation{n: n}
+}
+
+// IsLeader reports whether id is the leader for view.
+func (r *Rotation) IsLeader(id identity.NodeID, view types.View) bool {
+	return r.FindLeaderFor(view) == id
+}
+
+// FindLeaderFor derives the leader for view as (view mod n) + 1.
+func (r *Rotation) FindLeaderFor(view types.View) identity.NodeID {
+	return identity.NodeID(uint64(view)%uint64(r.n) + 1)
+}
@@ -0,0 +1,111 @@
+// Package hotstuff implements chained (three-phase) HotStuff's safety
+// rules: vote once per view, lock two hops back, and commit on an
+// unbroken three-chain of QCs.
+package hotstuff
+
+import (
+	"fmt"
+
+	"github.com/gitferry/zeitgeber/blockchain"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// HotStuff is a zeitgeber.Safety implementation backed by a
+// *blockchain.BlockChain.
+type HotStuff struct {
+	bc            *blockchain.BlockChain
+	name          string
+	lastVotedView types.View
+	lockedQC      *blockchain.QC
+}
+
+// NewHotStuff creates a HotStuff safety module over bc. name
+// identifies the variant for logging; "default" is the only one wired
+// up today.
+func NewHotStuff(bc *blockchain.BlockChain, name string) *HotStuff {
+	return &HotStuff{bc: bc, name: name}
+}
+
+// VotingRule reports whether block may be voted for: its view must be
+// newer than the last one this replica voted in, and it must not
+// equivocate against the locked QC.
+func (hs *HotStuff) VotingRule(block *blockchain.Block) (bool, error) {
+	if block.View <= hs.lastVotedView {
+		return false, nil
+	}
+	if hs.lockedQC == nil {
+		return true, nil
+	}
+	return block.QC.View >= hs.lockedQC.View, nil
+}
+
+// UpdateStateByView records that the replica has now voted in view.
+func (hs *HotStuff) UpdateStateByView(view types.View) error {
+	if view <= hs.lastVotedView {
+		return fmt.Errorf("cannot update last voted view backwards, cur: %v, new: %v", hs.lastVotedView, view)
+	}
+	hs.lastVotedView = view
+	return nil
+}
+
+// UpdateStateByQC advances the locked QC to qc's parent: the two-chain
+// rule that makes a later conflicting proposal unvotable once this
+// replica has seen qc.
+func (hs *HotStuff) UpdateStateByQC(qc *blockchain.QC) error {
+	parent, err := hs.bc.GetParentBlock(qc.BlockID)
+	if err != nil {
+		return fmt.Errorf("cannot lock on qc's parent: %w", err)
+	}
+	if hs.lockedQC == nil || parent.QC.View > hs.lockedQC.View {
+		hs.lockedQC = parent.QC
+	}
+	return nil
+}
+
+// CommitRule applies the three-chain commit rule: once qc's block, its
+// parent and its grandparent form an unbroken sequence of views, the
+// grandparent is safe to commit.
+func (hs *HotStuff) CommitRule(qc *blockchain.QC) (bool, *blockchain.Block, error) {
+	block, err := hs.bc.GetBlock(qc.BlockID)
+	if err != nil {
+		return false, nil, fmt.Errorf("cannot find qc's block: %w", err)
+	}
+	parent, err := hs.bc.GetParentBlock(qc.BlockID)
+	if err != nil {
+		return false, nil, nil
+	}
+	grandparent, err := hs.bc.GetGrandParentBlock(qc.BlockID)
+	if err != nil {
+		return false, nil, nil
+	}
+	if parent.View+1 == block.View && grandparent.View+1 == parent.View {
+		return true, grandparent, nil
+	}
+	return false, nil, nil
+}
+
+// Forkchoice returns the highest QC this replica has observed, the one
+// the next proposal should extend.
+func (hs *HotStuff) Forkchoice() *blockchain.QC {
+	return hs.bc.GetHighQC()
+}
+
+// NextPhase reports that chained HotStuff is single-phase: every QC is
+// terminal and immediately advances the view.
+func (hs *HotStuff) NextPhase(qc *blockchain.QC) (blockchain.Phase, bool) {
+	return 0, false
+}
+
+// SafetyState returns the last view this replica voted in and the QC
+// it is currently locked on, for WAL snapshotting.
+func (hs *HotStuff) SafetyState() (types.View, *blockchain.QC) {
+	return hs.lastVotedView, hs.lockedQC
+}
+
+// RestoreSafetyState restores safety state previously captured by
+// SafetyState, used when replaying a WAL whose older segments have
+// already been compacted away.
+func (hs *HotStuff) RestoreSafetyState(lastVotedView types.View, lockedQC *blockchain.QC) {
+	hs.lastVotedView = lastVotedView
+	hs.lockedQC = lockedQC
+}
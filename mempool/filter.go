@@ -0,0 +1,77 @@
+package mempool
+
+import (
+	"hash/fnv"
+	"math"
+
+	"github.com/gitferry/zeitgeber/crypto"
+)
+
+// Filter is a standard k-hash Bloom filter over transaction
+// identifiers, compact enough to gossip to every peer on a fixed
+// interval as a stand-in for the transactions themselves. Fields are
+// exported so it survives the gob encoding Send/Broadcast apply to
+// every message.
+type Filter struct {
+	Bits []uint64
+	M    uint
+	K    uint
+}
+
+// NewFilter sizes a Bloom filter for n expected items at false-positive
+// rate p.
+func NewFilter(n int, p float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	m := optimalM(n, p)
+	k := optimalK(m, n)
+	return &Filter{Bits: make([]uint64, (m+63)/64), M: m, K: k}
+}
+
+// Add records id as present in the filter.
+func (f *Filter) Add(id crypto.Identifier) {
+	for i := uint(0); i < f.K; i++ {
+		idx := f.index(id, i)
+		f.Bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+// Test reports whether id is possibly present: false means definitely
+// absent, true means present or a false positive at rate p.
+func (f *Filter) Test(id crypto.Identifier) bool {
+	for i := uint(0); i < f.K; i++ {
+		idx := f.index(id, i)
+		if f.Bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// index derives the seed-th bit position for id by running fnv-1a over
+// id followed by the seed byte, so each of the k rounds is the same
+// hash function salted differently rather than k independent ones.
+func (f *Filter) index(id crypto.Identifier, seed uint) uint {
+	h := fnv.New64a()
+	h.Write(id[:])
+	h.Write([]byte{byte(seed)})
+	return uint(h.Sum64() % uint64(f.M))
+}
+
+// optimalM returns the bit-array size minimizing space for n items at
+// false-positive rate p.
+func optimalM(n int, p float64) uint {
+	m := -float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)
+	return uint(math.Ceil(m))
+}
+
+// optimalK returns the number of hash rounds minimizing false
+// positives for a filter of size m holding n items.
+func optimalK(m uint, n int) uint {
+	k := math.Round(float64(m) / float64(n) * math.Ln2)
+	if k < 1 {
+		return 1
+	}
+	return uint(k)
+}
@@ -0,0 +1,88 @@
+package mempool
+
+import (
+	"github.com/gitferry/zeitgeber/crypto"
+	"github.com/gitferry/zeitgeber/identity"
+	"github.com/gitferry/zeitgeber/message"
+)
+
+// defaultFalsePositiveRate bounds how often a Digest reports a
+// transaction as held that its sender does not actually have, so the
+// recipient skips announcing it in that round, in exchange for a much
+// smaller digest.
+const defaultFalsePositiveRate = 0.01
+
+// Digest is the message a replica gossips to its peers describing
+// which transactions it already holds, replacing a broadcast of every
+// transaction to every peer (quadratic in both transaction count and
+// replica count) with a fixed-size summary.
+type Digest struct {
+	NodeID identity.NodeID
+	Filter *Filter
+}
+
+// TxnAnnounce lists the transaction IDs the sender holds that the
+// recipient's Digest reported missing. The recipient pulls only the
+// ones it still wants via TxnRequest, rather than being sent every
+// announced body unconditionally.
+type TxnAnnounce struct {
+	NodeID identity.NodeID
+	TxnIDs []crypto.Identifier
+}
+
+// TxnRequest asks the peer that sent a TxnAnnounce for the bodies of
+// the listed transaction IDs.
+type TxnRequest struct {
+	NodeID identity.NodeID
+	TxnIDs []crypto.Identifier
+}
+
+// TxnBody carries the transaction bodies a peer asked for via
+// TxnRequest.
+type TxnBody struct {
+	Txns []*message.Transaction
+}
+
+// Digest summarizes p's pending transaction IDs in a Bloom filter sized
+// for the current mempool.
+func (p *Producer) Digest() *Filter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f := NewFilter(len(p.order), defaultFalsePositiveRate)
+	for id := range p.txns {
+		f.Add(id)
+	}
+	return f
+}
+
+// MissingIDs returns the IDs of every pending transaction peerDigest
+// reports as (probably) missing: the set a gossip round should
+// announce back to the peer that produced peerDigest.
+func (p *Producer) MissingIDs(peerDigest *Filter) []crypto.Identifier {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var missing []crypto.Identifier
+	for _, id := range p.order {
+		if peerDigest.Test(id) {
+			continue
+		}
+		if _, ok := p.txns[id]; ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// Txns returns the pending transactions among ids that p still holds,
+// used to answer a TxnRequest.
+func (p *Producer) Txns(ids []crypto.Identifier) []*message.Transaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var txns []*message.Transaction
+	for _, id := range ids {
+		if txn, ok := p.txns[id]; ok {
+			txns = append(txns, txn)
+		}
+	}
+	return txns
+}
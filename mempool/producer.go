@@ -0,0 +1,102 @@
+// Package mempool collects pending transactions and assembles them
+// into proposed blocks, gossiping them between replicas' mempools via
+// Bloom-filter reconciliation (see gossip.go) rather than a raw
+// broadcast of every transaction to every peer.
+package mempool
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+
+	"github.com/gitferry/zeitgeber/blockchain"
+	"github.com/gitferry/zeitgeber/crypto"
+	"github.com/gitferry/zeitgeber/identity"
+	"github.com/gitferry/zeitgeber/message"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// Producer holds a replica's pending transactions, in the order they
+// were first collected, and assembles them into blocks it proposes.
+type Producer struct {
+	mu    sync.Mutex
+	txns  map[crypto.Identifier]*message.Transaction
+	order []crypto.Identifier
+}
+
+// NewProducer creates an empty Producer.
+func NewProducer() *Producer {
+	return &Producer{txns: make(map[crypto.Identifier]*message.Transaction)}
+}
+
+// CollectTxn adds txn to the pending set, ignoring it if already held.
+func (p *Producer) CollectTxn(txn *message.Transaction) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.txns[txn.ID]; exists {
+		return
+	}
+	p.txns[txn.ID] = txn
+	p.order = append(p.order, txn.ID)
+}
+
+// HasTxn reports whether id is currently in the pending set.
+func (p *Producer) HasTxn(id crypto.Identifier) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, exists := p.txns[id]
+	return exists
+}
+
+// RemoveTxn drops id from the pending set, used once it has been
+// proposed or committed.
+func (p *Producer) RemoveTxn(id crypto.Identifier) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.txns[id]; !exists {
+		return
+	}
+	delete(p.txns, id)
+	for i, orderedID := range p.order {
+		if orderedID == id {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// ProduceBlock assembles a new block for view, extending qc, proposed
+// by proposer, carrying every transaction currently pending.
+func (p *Producer) ProduceBlock(view types.View, qc *blockchain.QC, proposer identity.NodeID) *blockchain.Block {
+	p.mu.Lock()
+	payload := make([]*message.Transaction, 0, len(p.order))
+	for _, id := range p.order {
+		if txn, ok := p.txns[id]; ok {
+			payload = append(payload, txn)
+		}
+	}
+	p.mu.Unlock()
+	block := &blockchain.Block{
+		View:     view,
+		QC:       qc,
+		Proposer: proposer,
+		Payload:  payload,
+	}
+	block.ID = blockHash(block)
+	return block
+}
+
+// blockHash derives a block's identifier from its view and the
+// transactions it carries.
+func blockHash(block *blockchain.Block) crypto.Identifier {
+	h := sha256.New()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(block.View))
+	h.Write(buf[:])
+	for _, txn := range block.Payload {
+		h.Write(txn.ID[:])
+	}
+	var id crypto.Identifier
+	copy(id[:], h.Sum(nil))
+	return id
+}
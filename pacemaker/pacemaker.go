@@ -0,0 +1,145 @@
+package pacemaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gitferry/zeitgeber/blockchain"
+	"github.com/gitferry/zeitgeber/crypto"
+	"github.com/gitferry/zeitgeber/identity"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+const (
+	baseTimeout = 2 * time.Second
+	maxTimeout  = 32 * time.Second
+)
+
+// TMO is the message a replica broadcasts when its per-view timer
+// expires without the view advancing. It carries the highest QC the
+// replica has observed so that, once a TC is formed, the next leader
+// can safely propose extending it.
+type TMO struct {
+	NodeID    identity.NodeID
+	View      types.View
+	HighQC    *blockchain.QC
+	Signature crypto.Signature
+}
+
+// Pacemaker drives view progression. It arms a per-view timer on every
+// view entry and, if the timer fires before the view advances, pushes
+// the stale view onto TimeoutEvent so the replica can broadcast a TMO.
+// Consecutive timeouts back the timer off exponentially so that a
+// replica that keeps missing its view does not retrigger faster than
+// the network can resolve the previous round.
+type Pacemaker struct {
+	mu                  sync.Mutex
+	curView             types.View
+	timer               *time.Timer
+	consecutiveTimeouts int
+	newViewChan         chan types.View
+	timeoutChan         chan types.View
+}
+
+// NewPacemaker creates a new pacemaker sitting at view 0.
+func NewPacemaker() *Pacemaker {
+	pm := new(Pacemaker)
+	pm.newViewChan = make(chan types.View, 1)
+	pm.timeoutChan = make(chan types.View, 1)
+	return pm
+}
+
+// GetCurView returns the view the pacemaker currently considers active.
+func (pm *Pacemaker) GetCurView() types.View {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	return pm.curView
+}
+
+// AdvanceView moves the pacemaker past view and (re)arms the timer for
+// the new view. It resets the timeout backoff, since a QC is proof that
+// the network made real progress in the view being left. A view that is
+// already stale is ignored.
+func (pm *Pacemaker) AdvanceView(view types.View) {
+	pm.advanceView(view, true)
+}
+
+// AdvanceViewOnTC moves the pacemaker past view without resetting the
+// timeout backoff. It is the TC counterpart of AdvanceView: a TC is
+// formed precisely because the view timed out, so the next view's timer
+// must keep the accumulated backoff rather than snap back to
+// baseTimeout.
+func (pm *Pacemaker) AdvanceViewOnTC(view types.View) {
+	pm.advanceView(view, false)
+}
+
+func (pm *Pacemaker) advanceView(view types.View, resetBackoff bool) {
+	pm.mu.Lock()
+	if view < pm.curView {
+		pm.mu.Unlock()
+		return
+	}
+	pm.curView = view + 1
+	if resetBackoff {
+		pm.consecutiveTimeouts = 0
+	}
+	next := pm.curView
+	pm.mu.Unlock()
+	pm.armTimer(next)
+	pm.newViewChan <- next
+}
+
+// RestoreView sets the pacemaker directly to view and arms its timer,
+// without resetting the timeout backoff or announcing on
+// EnteringViewEvent. Used to resume a view recovered from a WAL
+// snapshot, as opposed to AdvanceView's live, backoff-resetting
+// progression.
+func (pm *Pacemaker) RestoreView(view types.View) {
+	pm.mu.Lock()
+	pm.curView = view
+	pm.mu.Unlock()
+	pm.armTimer(view)
+}
+
+// EnteringViewEvent returns the channel on which the pacemaker announces
+// a newly entered view.
+func (pm *Pacemaker) EnteringViewEvent() chan types.View {
+	return pm.newViewChan
+}
+
+// TimeoutEvent returns the channel on which the pacemaker announces that
+// the timer for a view expired before the view advanced.
+func (pm *Pacemaker) TimeoutEvent() chan types.View {
+	return pm.timeoutChan
+}
+
+// armTimer (re)starts the per-view timer, backing off exponentially with
+// every consecutive timeout to damp cascading view changes under
+// asynchrony.
+func (pm *Pacemaker) armTimer(view types.View) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if pm.timer != nil {
+		pm.timer.Stop()
+	}
+	timeout := baseTimeout << uint(pm.consecutiveTimeouts)
+	if timeout <= 0 || timeout > maxTimeout {
+		timeout = maxTimeout
+	}
+	pm.timer = time.AfterFunc(timeout, func() {
+		pm.onTimeout(view)
+	})
+}
+
+// onTimeout fires when a view's timer expires. It is a no-op if the
+// pacemaker has already moved past the view by other means (e.g. a QC).
+func (pm *Pacemaker) onTimeout(view types.View) {
+	pm.mu.Lock()
+	if view < pm.curView {
+		pm.mu.Unlock()
+		return
+	}
+	pm.consecutiveTimeouts++
+	pm.mu.Unlock()
+	pm.timeoutChan <- view
+}
@@ -1,12 +1,16 @@
 package zeitgeber
 
 import (
+	"bytes"
 	"encoding/gob"
+	"math/rand"
 	"sync"
 	"time"
 
+	"github.com/gitferry/zeitgeber/beacon"
 	"github.com/gitferry/zeitgeber/blockchain"
 	"github.com/gitferry/zeitgeber/config"
+	"github.com/gitferry/zeitgeber/crypto"
 	"github.com/gitferry/zeitgeber/election"
 	"github.com/gitferry/zeitgeber/hotstuff"
 	"github.com/gitferry/zeitgeber/identity"
@@ -14,23 +18,47 @@ import (
 	"github.com/gitferry/zeitgeber/mempool"
 	"github.com/gitferry/zeitgeber/message"
 	"github.com/gitferry/zeitgeber/pacemaker"
+	"github.com/gitferry/zeitgeber/tendermint"
+	"github.com/gitferry/zeitgeber/twochain"
 	"github.com/gitferry/zeitgeber/types"
+	"github.com/gitferry/zeitgeber/wal"
 )
 
+// compactEvery bounds how many commits accumulate between WAL
+// compactions, trading a bit of replay work after a crash for not
+// fsyncing a fresh snapshot on every single commit.
+const compactEvery = 100
+
+// gossipInterval is how often a replica gossips its mempool digest to
+// its peers.
+const gossipInterval = 500 * time.Millisecond
+
+// gossipFanout bounds how many peers a single gossip round reaches: a
+// small random subset rather than every peer, so digest traffic stays
+// flat as the replica count grows instead of scaling with it.
+const gossipFanout = 3
+
 type Replica struct {
 	Node
 	election.Election
 	Safety
-	pd         *mempool.Producer
-	bc         *blockchain.BlockChain
-	pm         *pacemaker.Pacemaker
-	isStarted  bool
-	blockMsg   chan *blockchain.Block
-	voteMsg    chan *blockchain.Vote
-	qcMsg      chan *blockchain.QC
-	timeoutMsg chan *pacemaker.TMO
-	newView    chan types.View
-	mu         sync.Mutex
+	pd          *mempool.Producer
+	bc          *blockchain.BlockChain
+	pm          *pacemaker.Pacemaker
+	isStarted   bool
+	blockMsg    chan *blockchain.Block
+	voteMsg     chan *blockchain.Vote
+	qcMsg       chan *blockchain.QC
+	timeoutMsg  chan *pacemaker.TMO
+	digestMsg   chan *mempool.Digest
+	announceMsg chan *mempool.TxnAnnounce
+	requestMsg  chan *mempool.TxnRequest
+	bodyMsg     chan *mempool.TxnBody
+	newView     chan types.View
+	wal         *wal.WAL
+	compactedAt int
+	replaying   bool
+	mu          sync.Mutex
 }
 
 // NewReplica creates a new replica instance
@@ -40,31 +68,164 @@ func NewReplica(id identity.NodeID, alg string, isByz bool) *Replica {
 	if isByz {
 		log.Infof("[%v] is Byzantine", r.ID())
 	}
-	r.Election = election.NewRotation(config.GetConfig().N())
+	if config.GetConfig().UseBeaconElection() {
+		r.Election = election.NewBeaconElection(beacon.NewDrandBeacon(), config.GetConfig().N())
+	} else {
+		r.Election = election.NewRotation(config.GetConfig().N())
+	}
 	bc := blockchain.NewBlockchain(config.GetConfig().N())
 	r.bc = bc
+	groupPK, shares := crypto.KeyGen(config.GetConfig().N(), config.GetConfig().F()+1, config.GetConfig().KeyGenSeed())
+	// KeyGen derives the dealer's secret from config.GetConfig().KeyGenSeed(),
+	// a value every replica's deployment config shares out of band, so
+	// every replica process - each of which runs this same KeyGen call
+	// independently - reconstructs identical share material without a
+	// live distribution round trip.
+	nodePKs := make(map[identity.NodeID]crypto.PublicKey, len(shares))
+	for i, share := range shares {
+		nodePKs[identity.NodeID(i+1)] = share.PublicKey
+	}
+	bc.SetNodePublicKeys(nodePKs)
+	if config.GetConfig().SignatureScheme() == "noop" {
+		// isolate pure consensus overhead for benchmarks that don't
+		// want cryptographic cost in the critical path
+		bc.SetSigner(groupPK, crypto.NoopSigner{})
+	} else {
+		// NodeID is this replica's 1-indexed position among the n
+		// participants KeyGen was just run for
+		bc.SetSigner(groupPK, crypto.NewBLSSigner(shares[int(id)-1]))
+	}
 	r.pd = mempool.NewProducer()
 	r.pm = pacemaker.NewPacemaker()
 	r.blockMsg = make(chan *blockchain.Block, 1)
 	r.voteMsg = make(chan *blockchain.Vote, 1)
 	r.qcMsg = make(chan *blockchain.QC, 1)
 	r.timeoutMsg = make(chan *pacemaker.TMO, 1)
+	r.digestMsg = make(chan *mempool.Digest, 1)
+	r.announceMsg = make(chan *mempool.TxnAnnounce, 1)
+	r.requestMsg = make(chan *mempool.TxnRequest, 1)
+	r.bodyMsg = make(chan *mempool.TxnBody, 1)
 	r.Register(blockchain.QC{}, r.HandleQC)
 	r.Register(blockchain.Block{}, r.HandleBlock)
 	r.Register(blockchain.Vote{}, r.HandleVote)
+	r.Register(pacemaker.TMO{}, r.HandleTMO)
 	r.Register(message.Transaction{}, r.handleTxn)
+	r.Register(mempool.Digest{}, r.HandleDigest)
+	r.Register(mempool.TxnAnnounce{}, r.HandleTxnAnnounce)
+	r.Register(mempool.TxnRequest{}, r.HandleTxnRequest)
+	r.Register(mempool.TxnBody{}, r.HandleTxnBody)
 	gob.Register(blockchain.Block{})
 	gob.Register(blockchain.QC{})
 	gob.Register(blockchain.Vote{})
+	gob.Register(pacemaker.TMO{})
+	gob.Register(mempool.Digest{})
+	gob.Register(mempool.TxnAnnounce{})
+	gob.Register(mempool.TxnRequest{})
+	gob.Register(mempool.TxnBody{})
 	switch alg {
-	case "hotsutff":
+	case "hotstuff":
 		r.Safety = hotstuff.NewHotStuff(bc, "default")
+	case "tendermint":
+		r.Safety = tendermint.NewTendermint(bc, "default")
+	case "twochain":
+		r.Safety = twochain.NewTwoChain(bc, "default")
 	default:
 		r.Safety = hotstuff.NewHotStuff(bc, "default")
 	}
+	w, err := wal.Open(config.GetConfig().WALDir(id))
+	if err != nil {
+		log.Errorf("[%v] cannot open wal, crash recovery is disabled: %w", r.ID(), err)
+	} else {
+		r.wal = w
+		r.replayWAL()
+	}
 	return r
 }
 
+// replayWAL restores highQC, lastVotedView, lockedQC and the current
+// view by re-running every safety-relevant event recorded before the
+// last crash through the same processing functions used on the live
+// path, with network effects suppressed.
+func (r *Replica) replayWAL() {
+	r.replaying = true
+	defer func() { r.replaying = false }()
+	err := wal.Replay(config.GetConfig().WALDir(r.ID()), func(t wal.RecordType, payload []byte) error {
+		switch t {
+		case wal.Snapshot:
+			var snap blockchain.Snapshot
+			if err := decodeGob(payload, &snap); err != nil {
+				return err
+			}
+			if err := r.bc.RestoreSnapshot(snap); err != nil {
+				return err
+			}
+			r.Safety.RestoreSafetyState(snap.LastVotedView, snap.LockedQC)
+			r.pm.RestoreView(snap.CurView)
+			return nil
+		case wal.BlockReceived:
+			var block blockchain.Block
+			if err := decodeGob(payload, &block); err != nil {
+				return err
+			}
+			r.processBlock(&block)
+		case wal.VoteCast:
+			var vote blockchain.Vote
+			if err := decodeGob(payload, &vote); err != nil {
+				return err
+			}
+			r.processVote(&vote)
+		case wal.QCFormed:
+			var qc blockchain.QC
+			if err := decodeGob(payload, &qc); err != nil {
+				return err
+			}
+			r.processCertificate(&qc)
+		case wal.TCFormed:
+			var tc blockchain.TC
+			if err := decodeGob(payload, &tc); err != nil {
+				return err
+			}
+			r.processTC(&tc)
+		case wal.ViewEntered:
+			// a consequence of the QC/TC replayed above; no separate
+			// state to restore
+		}
+		return nil
+	})
+	if err != nil {
+		log.Errorf("[%v] wal replay failed: %w", r.ID(), err)
+	}
+}
+
+// appendWAL records a safety-relevant event, logging rather than
+// failing the caller if the WAL isn't available (disabled, or this
+// call is itself happening during replay).
+func (r *Replica) appendWAL(t wal.RecordType, v interface{}) {
+	if r.wal == nil || r.replaying {
+		return
+	}
+	payload, err := encodeGob(v)
+	if err != nil {
+		log.Errorf("[%v] cannot encode wal record: %w", r.ID(), err)
+		return
+	}
+	if err := r.wal.Append(t, payload); err != nil {
+		log.Errorf("[%v] cannot append wal record: %w", r.ID(), err)
+	}
+}
+
+func encodeGob(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeGob(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
 /* Message Handlers */
 
 func (r *Replica) HandleBlock(block blockchain.Block) {
@@ -91,6 +252,30 @@ func (r *Replica) HandleQC(qc blockchain.QC) {
 	r.qcMsg <- &qc
 }
 
+func (r *Replica) HandleTMO(tmo pacemaker.TMO) {
+	log.Debugf("[%v] received a tmo from %v, view is %v", r.ID(), tmo.NodeID, tmo.View)
+	if tmo.View < r.pm.GetCurView() {
+		return
+	}
+	r.timeoutMsg <- &tmo
+}
+
+func (r *Replica) HandleDigest(digest mempool.Digest) {
+	r.digestMsg <- &digest
+}
+
+func (r *Replica) HandleTxnAnnounce(announce mempool.TxnAnnounce) {
+	r.announceMsg <- &announce
+}
+
+func (r *Replica) HandleTxnRequest(req mempool.TxnRequest) {
+	r.requestMsg <- &req
+}
+
+func (r *Replica) HandleTxnBody(body mempool.TxnBody) {
+	r.bodyMsg <- &body
+}
+
 func (r *Replica) handleTxn(m message.Transaction) {
 	//log.Debugf("[%v] received txn %v\n", r.ID(), m)
 	r.mu.Lock()
@@ -107,19 +292,39 @@ func (r *Replica) handleTxn(m message.Transaction) {
 
 func (r *Replica) processBlock(block *blockchain.Block) {
 	log.Debugf("[%v] is processing block, view: %v, id: %x", r.ID(), block.View, block.ID)
-	// TODO: process TC
+	r.appendWAL(wal.BlockReceived, block)
+	if block.QC.View+1 != block.View {
+		// the parent QC does not directly precede this block's view, so
+		// the proposal is only acceptable if it carries a TC proving a
+		// supermajority gave up on the skipped view(s)
+		if block.TC == nil || block.TC.View != block.View-1 {
+			log.Warningf("[%v] received a block extending a stale QC without a valid TC, view: %v", r.ID(), block.View)
+			return
+		}
+		r.processTC(block.TC)
+	}
 	r.processCertificate(block.QC)
 	curView := r.pm.GetCurView()
 	if block.View != curView {
 		log.Warningf("[%v] received a stale proposal", r.ID())
 		return
 	}
+	if be, ok := r.Election.(*election.BeaconElection); ok {
+		if err := be.VerifyProposal(block.View, block.BeaconEntry); err != nil {
+			log.Warningf("[%v] rejecting proposal (%v) with an invalid beacon entry: %v", r.ID(), block.View, err)
+			return
+		}
+	}
 	if !r.Election.IsLeader(block.Proposer, block.View) {
 		log.Warningf(
 			"[%v] received a proposal (%v) from an invalid leader (%v)",
 			r.ID(), block.View, block.Proposer)
 		return
 	}
+	if err := block.Verify(r.bc.PubKeyFor(block.Proposer)); err != nil {
+		log.Warningf("[%v] rejecting proposal (%v) with an invalid signature: %v", r.ID(), block.View, err)
+		return
+	}
 	r.bc.AddBlock(block)
 	shouldVote, err := r.VotingRule(block)
 	if err != nil {
@@ -131,13 +336,18 @@ func (r *Replica) processBlock(block *blockchain.Block) {
 		return
 	}
 	log.Debugf("[%v] is going to vote for block, id: %x", r.ID(), block.ID)
-	vote := blockchain.MakeVote(block.View, r.ID(), block.ID)
+	vote := blockchain.NewVote(block.View, block.ID, blockchain.Prepare)
+	vote.Voter = r.ID()
 	err = r.UpdateStateByView(vote.View)
 	if err != nil {
 		log.Errorf("cannot update state after voting: %w", err)
 	}
-	// TODO: sign the vote
+	vote.Signature = r.bc.Sign(blockchain.VoteMessage(vote.View, vote.BlockID))
+	r.appendWAL(wal.VoteCast, vote)
 	time.Sleep(20 * time.Millisecond)
+	if r.replaying {
+		return
+	}
 	voteAggregator := r.FindLeaderFor(curView + 1)
 	if voteAggregator == r.ID() {
 		r.processVote(vote)
@@ -150,6 +360,17 @@ func (r *Replica) processCertificate(qc *blockchain.QC) {
 	if qc.View < r.pm.GetCurView() {
 		return
 	}
+	if err := qc.Verify(r.bc.GroupPK()); err != nil {
+		log.Warningf("[%v] dropping a qc that fails verification, view: %v: %v", r.ID(), qc.View, err)
+		return
+	}
+	r.appendWAL(wal.QCFormed, qc)
+	if phase, ok := r.NextPhase(qc); ok {
+		// a multi-phase safety rule (e.g. Tendermint's precommit) still
+		// needs a same-view follow-up vote before the view can advance
+		r.voteNextPhase(qc, phase)
+		return
+	}
 	r.pm.AdvanceView(qc.View)
 	log.Debugf("[%v] has advanced to view %v", r.ID(), r.pm.GetCurView())
 	err := r.UpdateStateByQC(qc)
@@ -180,10 +401,139 @@ func (r *Replica) processCertificate(qc *blockchain.QC) {
 	r.processCommittedBlocks(committedBlocks)
 }
 
+// processTC advances the view on a timeout certificate, the TC
+// counterpart of processCertificate's QC-driven advancement. It also
+// refreshes the blockchain's high QC with the highest QC carried by the
+// TC, since that is what the next leader must extend.
+func (r *Replica) processTC(tc *blockchain.TC) {
+	if tc.View < r.pm.GetCurView() {
+		return
+	}
+	if err := tc.Verify(r.bc.NodePublicKeys(), r.bc.QuorumSize()); err != nil {
+		log.Warningf("[%v] dropping a tc that fails verification, view: %v: %v", r.ID(), tc.View, err)
+		return
+	}
+	r.appendWAL(wal.TCFormed, tc)
+	r.pm.AdvanceViewOnTC(tc.View)
+	log.Debugf("[%v] has advanced to view %v via TC", r.ID(), r.pm.GetCurView())
+	if err := r.bc.UpdateHighQC(tc.HighQC); err != nil {
+		log.Warningf("[%v] tc carries a stale high qc, view: %v", r.ID(), tc.View)
+	}
+}
+
+// processTMO folds a received TMO into the blockchain's TC quorum for
+// its view, forming and processing a TC once a supermajority of
+// replicas have given up on that view.
+func (r *Replica) processTMO(tmo *pacemaker.TMO) {
+	r.mu.Lock()
+	isBuilt, tc := r.bc.AddTMO(tmo.View, tmo.NodeID, tmo.HighQC, tmo.Signature)
+	r.mu.Unlock()
+	if !isBuilt {
+		return
+	}
+	r.processTC(tc)
+}
+
+// gossipMempool sends a Bloom-filter digest of this replica's pending
+// transactions to a small random subset of its peers, in place of
+// broadcasting every transaction - or even the digest itself - to
+// every peer. A peer that receives the digest replies with the IDs of
+// the transactions this replica is missing, and this replica then pulls
+// only the bodies it still wants.
+func (r *Replica) gossipMempool() {
+	if r.replaying {
+		return
+	}
+	digest := mempool.Digest{NodeID: r.ID(), Filter: r.pd.Digest()}
+	for _, peer := range r.gossipPeers() {
+		go r.Send(peer, digest)
+	}
+}
+
+// gossipPeers draws up to gossipFanout peers at random from the
+// configured node set, excluding this replica itself.
+func (r *Replica) gossipPeers() []identity.NodeID {
+	all := config.GetConfig().Peers()
+	candidates := make([]identity.NodeID, 0, len(all))
+	for _, id := range all {
+		if id != r.ID() {
+			candidates = append(candidates, id)
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	if len(candidates) > gossipFanout {
+		candidates = candidates[:gossipFanout]
+	}
+	return candidates
+}
+
+// processDigest replies to a peer's mempool digest by announcing the
+// IDs of the transactions it reports as missing, if any, letting the
+// peer pull only the bodies it still wants via TxnRequest.
+func (r *Replica) processDigest(digest *mempool.Digest) {
+	missing := r.pd.MissingIDs(digest.Filter)
+	if len(missing) == 0 {
+		return
+	}
+	r.Send(digest.NodeID, mempool.TxnAnnounce{NodeID: r.ID(), TxnIDs: missing})
+}
+
+// processTxnAnnounce requests the bodies of every announced
+// transaction ID this replica doesn't already hold.
+func (r *Replica) processTxnAnnounce(announce *mempool.TxnAnnounce) {
+	var want []crypto.Identifier
+	for _, id := range announce.TxnIDs {
+		if !r.pd.HasTxn(id) {
+			want = append(want, id)
+		}
+	}
+	if len(want) == 0 {
+		return
+	}
+	r.Send(announce.NodeID, mempool.TxnRequest{NodeID: r.ID(), TxnIDs: want})
+}
+
+// processTxnRequest replies to a peer's TxnRequest with the bodies of
+// the transactions it asked for that this replica still holds.
+func (r *Replica) processTxnRequest(req *mempool.TxnRequest) {
+	txns := r.pd.Txns(req.TxnIDs)
+	if len(txns) == 0 {
+		return
+	}
+	r.Send(req.NodeID, mempool.TxnBody{Txns: txns})
+}
+
+// processTxnBody folds transaction bodies received from a peer's
+// TxnRequest reply into this replica's own mempool.
+func (r *Replica) processTxnBody(body *mempool.TxnBody) {
+	for _, txn := range body.Txns {
+		r.pd.CollectTxn(txn)
+	}
+}
+
+// processLocalTimeout fires when the pacemaker's per-view timer expires
+// without the view advancing. The replica broadcasts a TMO carrying its
+// highest known QC and folds it into its own TC quorum.
+func (r *Replica) processLocalTimeout(view types.View) {
+	log.Debugf("[%v] timed out in view %v", r.ID(), view)
+	tmo := &pacemaker.TMO{
+		NodeID:    r.ID(),
+		View:      view,
+		HighQC:    r.bc.GetHighQC(),
+		Signature: r.bc.Sign(blockchain.TCMessage(view)),
+	}
+	if !r.replaying {
+		r.Broadcast(*tmo)
+	}
+	r.processTMO(tmo)
+}
+
 func (r *Replica) processCommittedBlocks(blocks []*blockchain.Block) {
 	for _, block := range blocks {
 		for _, txn := range block.Payload {
-			if r.ID() == txn.NodeID {
+			if r.ID() == txn.NodeID && !r.replaying {
 				txn.Reply(message.TransactionReply{})
 			}
 			if r.ID() != block.Proposer { // txns are removed when being proposed
@@ -198,6 +548,64 @@ func (r *Replica) processCommittedBlocks(blocks []*blockchain.Block) {
 	//	print measurement
 	log.Infof("[%v] Committed blocks: %v, total blocks: %v, chain growth: %v", r.ID(), r.bc.GetTotalBlock(), r.bc.GetChainGrowth())
 	log.Infof("[%v] Honest committed blocks: %v, committed blocks: %v, chain quality: %v", r.ID(), r.bc.GetHonestCommittedBlock(), r.bc.GetChainQuality())
+	r.maybeCompactWAL()
+}
+
+// maybeCompactWAL snapshots committed state and truncates the WAL once
+// compactEvery blocks have committed since the last compaction, so a
+// replay after a crash never has to walk much more than compactEvery
+// commits' worth of segments.
+func (r *Replica) maybeCompactWAL() {
+	if r.wal == nil || r.replaying {
+		return
+	}
+	committed := r.bc.GetCommittedBlock()
+	if committed-r.compactedAt < compactEvery {
+		return
+	}
+	lastVotedView, lockedQC := r.Safety.SafetyState()
+	payload, err := encodeGob(r.bc.MakeSnapshot(lastVotedView, lockedQC, r.pm.GetCurView()))
+	if err != nil {
+		log.Errorf("[%v] cannot encode wal snapshot: %w", r.ID(), err)
+		return
+	}
+	dir := config.GetConfig().WALDir(r.ID())
+	if err := r.wal.Close(); err != nil {
+		log.Errorf("[%v] cannot close wal before compaction: %w", r.ID(), err)
+		return
+	}
+	if err := wal.Compact(dir, payload); err != nil {
+		log.Errorf("[%v] cannot compact wal: %w", r.ID(), err)
+	} else {
+		r.compactedAt = committed
+	}
+	w, err := wal.Open(dir)
+	if err != nil {
+		log.Errorf("[%v] cannot reopen wal after compaction: %w", r.ID(), err)
+		return
+	}
+	r.wal = w
+}
+
+// voteNextPhase casts this replica's vote for qc's block in phase,
+// continuing a multi-phase safety rule without advancing the view. It
+// is sent to the same aggregator as the vote that produced qc, so that
+// leader collects every phase's quorum before proposing the next block.
+func (r *Replica) voteNextPhase(qc *blockchain.QC, phase blockchain.Phase) {
+	vote := blockchain.NewVote(qc.View, qc.BlockID, phase)
+	vote.Voter = r.ID()
+	vote.Signature = r.bc.Sign(blockchain.VoteMessage(vote.View, vote.BlockID))
+	r.appendWAL(wal.VoteCast, vote)
+	time.Sleep(20 * time.Millisecond)
+	if r.replaying {
+		return
+	}
+	voteAggregator := r.FindLeaderFor(qc.View + 1)
+	if voteAggregator == r.ID() {
+		r.processVote(vote)
+	} else {
+		r.Send(voteAggregator, vote)
+	}
 }
 
 func (r *Replica) processVote(vote *blockchain.Vote) {
@@ -223,10 +631,20 @@ func (r *Replica) proposeBlock(view types.View) {
 	r.mu.Lock()
 	block := r.pd.ProduceBlock(view, r.Safety.Forkchoice(), r.ID())
 	r.mu.Unlock()
-	//	TODO: sign the block
+	if highTC := r.bc.GetHighTC(); highTC != nil && highTC.View == view-1 {
+		// the previous view ended in a timeout: extend the highest QC
+		// the TC attests to and carry the TC along as proof
+		block.TC = highTC
+	}
+	if be, ok := r.Election.(*election.BeaconElection); ok {
+		block.BeaconEntry, _ = be.Entry(view)
+	}
+	block.Signature = r.bc.Sign(blockchain.BlockMessage(block.View, block.ID))
 	// simulate processing time
 	time.Sleep(50 * time.Millisecond)
-	r.Broadcast(block)
+	if !r.replaying {
+		r.Broadcast(block)
+	}
 	r.processBlock(block)
 	for _, txn := range block.Payload {
 		r.pd.RemoveTxn(txn.ID)
@@ -235,17 +653,33 @@ func (r *Replica) proposeBlock(view types.View) {
 
 func (r *Replica) Start() {
 	go r.Run()
+	gossipTicker := time.NewTicker(gossipInterval)
+	defer gossipTicker.Stop()
 	for {
-		// TODO: add timeout handler
 		select {
 		case newView := <-r.pm.EnteringViewEvent():
+			r.appendWAL(wal.ViewEntered, newView)
 			go r.processNewView(newView)
+		case timedOutView := <-r.pm.TimeoutEvent():
+			go r.processLocalTimeout(timedOutView)
 		case newBlock := <-r.blockMsg:
 			go r.processBlock(newBlock)
 		case newVote := <-r.voteMsg:
 			go r.processVote(newVote)
 		case newQC := <-r.qcMsg:
 			go r.processCertificate(newQC)
+		case newTMO := <-r.timeoutMsg:
+			go r.processTMO(newTMO)
+		case digest := <-r.digestMsg:
+			go r.processDigest(digest)
+		case announce := <-r.announceMsg:
+			go r.processTxnAnnounce(announce)
+		case req := <-r.requestMsg:
+			go r.processTxnRequest(req)
+		case body := <-r.bodyMsg:
+			go r.processTxnBody(body)
+		case <-gossipTicker.C:
+			go r.gossipMempool()
 		}
 	}
 }
@@ -0,0 +1,41 @@
+package zeitgeber
+
+import (
+	"github.com/gitferry/zeitgeber/blockchain"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// Safety abstracts a consensus protocol's voting, locking and commit
+// rules away from the rest of the replica, so alternative protocols
+// (chained HotStuff, Tendermint-style locking, ...) can be swapped in
+// behind the same view-driven event loop without touching it. Every
+// implementation is built against a single *blockchain.BlockChain and
+// is not safe for concurrent use; the replica serializes access to it
+// under its own mutex.
+type Safety interface {
+	// VotingRule reports whether block may be voted for given the
+	// replica's current locked state.
+	VotingRule(block *blockchain.Block) (bool, error)
+	// UpdateStateByView records that the replica has voted in view.
+	UpdateStateByView(view types.View) error
+	// UpdateStateByQC folds a newly formed QC into the replica's
+	// locked state.
+	UpdateStateByQC(qc *blockchain.QC) error
+	// CommitRule reports whether qc makes a block safe to commit.
+	CommitRule(qc *blockchain.QC) (bool, *blockchain.Block, error)
+	// Forkchoice returns the QC the next proposal from this replica
+	// should extend.
+	Forkchoice() *blockchain.QC
+	// NextPhase reports whether qc's block still needs a same-view
+	// follow-up vote (e.g. Tendermint's precommit, once its prevote
+	// quorum forms) before the view can advance. A single-phase
+	// protocol always returns false: every QC it sees is terminal.
+	NextPhase(qc *blockchain.QC) (blockchain.Phase, bool)
+	// SafetyState returns the last view this replica voted in and the
+	// QC it is currently locked on (nil if none), for WAL snapshotting.
+	SafetyState() (types.View, *blockchain.QC)
+	// RestoreSafetyState restores safety state previously captured by
+	// SafetyState, used when replaying a WAL whose older segments have
+	// already been compacted away.
+	RestoreSafetyState(lastVotedView types.View, lockedQC *blockchain.QC)
+}
@@ -0,0 +1,112 @@
+// Package tendermint implements a two-phase (prevote/precommit),
+// lock-based alternative to chained HotStuff: a replica locks on the
+// first block it sees precommitted and will not vote for a conflicting
+// proposal unless that proposal carries a QC from a later view than the
+// lock.
+package tendermint
+
+import (
+	"fmt"
+
+	"github.com/gitferry/zeitgeber/blockchain"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// Tendermint is a zeitgeber.Safety implementation backed by a
+// *blockchain.BlockChain.
+type Tendermint struct {
+	bc            *blockchain.BlockChain
+	name          string
+	lastVotedView types.View
+	lockedQC      *blockchain.QC
+}
+
+// NewTendermint creates a Tendermint safety module over bc. name
+// identifies the variant for logging; "default" is the only one wired
+// up today.
+func NewTendermint(bc *blockchain.BlockChain, name string) *Tendermint {
+	return &Tendermint{bc: bc, name: name}
+}
+
+// VotingRule reports whether block may be prevoted for: its view must
+// be newer than the last one this replica voted in, and it must either
+// extend the locked QC's block or carry a higher QC that justifies
+// unlocking.
+func (tm *Tendermint) VotingRule(block *blockchain.Block) (bool, error) {
+	if block.View <= tm.lastVotedView {
+		return false, nil
+	}
+	if tm.lockedQC == nil {
+		return true, nil
+	}
+	if block.QC.BlockID == tm.lockedQC.BlockID {
+		return true, nil
+	}
+	return block.QC.View > tm.lockedQC.View, nil
+}
+
+// UpdateStateByView records that the replica has now voted in view.
+func (tm *Tendermint) UpdateStateByView(view types.View) error {
+	if view <= tm.lastVotedView {
+		return fmt.Errorf("cannot update last voted view backwards, cur: %v, new: %v", tm.lastVotedView, view)
+	}
+	tm.lastVotedView = view
+	return nil
+}
+
+// UpdateStateByQC locks onto qc once it certifies a precommit: this
+// fires a round earlier than HotStuff's two-chain rule, as soon as
+// 2f+1 replicas precommit rather than waiting for a further QC.
+func (tm *Tendermint) UpdateStateByQC(qc *blockchain.QC) error {
+	if qc.Phase != blockchain.Precommit {
+		return nil
+	}
+	if tm.lockedQC == nil || qc.View > tm.lockedQC.View {
+		tm.lockedQC = qc
+	}
+	return nil
+}
+
+// CommitRule commits qc's block directly once qc certifies a
+// precommit: unlike HotStuff's three-chain rule, a single precommit
+// quorum is final.
+func (tm *Tendermint) CommitRule(qc *blockchain.QC) (bool, *blockchain.Block, error) {
+	if qc.Phase != blockchain.Precommit {
+		return false, nil, nil
+	}
+	block, err := tm.bc.GetBlock(qc.BlockID)
+	if err != nil {
+		return false, nil, fmt.Errorf("cannot find qc's block: %w", err)
+	}
+	return true, block, nil
+}
+
+// Forkchoice returns the highest QC this replica has observed, the one
+// the next proposal should extend.
+func (tm *Tendermint) Forkchoice() *blockchain.QC {
+	return tm.bc.GetHighQC()
+}
+
+// NextPhase reports that a Prepare-phase QC (this protocol's prevote
+// quorum) requires a same-view Precommit vote before the view can
+// advance; a Precommit QC is terminal.
+func (tm *Tendermint) NextPhase(qc *blockchain.QC) (blockchain.Phase, bool) {
+	if qc.Phase == blockchain.Prepare {
+		return blockchain.Precommit, true
+	}
+	return 0, false
+}
+
+// SafetyState returns the last view this replica voted in and the QC
+// it is currently locked on, for WAL snapshotting.
+func (tm *Tendermint) SafetyState() (types.View, *blockchain.QC) {
+	return tm.lastVotedView, tm.lockedQC
+}
+
+// RestoreSafetyState restores safety state previously captured by
+// SafetyState, used when replaying a WAL whose older segments have
+// already been compacted away.
+func (tm *Tendermint) RestoreSafetyState(lastVotedView types.View, lockedQC *blockchain.QC) {
+	tm.lastVotedView = lastVotedView
+	tm.lockedQC = lockedQC
+}
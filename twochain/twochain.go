@@ -0,0 +1,104 @@
+// Package twochain implements chained HotStuff's two-chain variant:
+// lock on the most recently seen QC directly (rather than its parent)
+// and commit as soon as two consecutive QCs form an unbroken chain,
+// one round sooner than chained HotStuff's three-chain rule.
+package twochain
+
+import (
+	"fmt"
+
+	"github.com/gitferry/zeitgeber/blockchain"
+	"github.com/gitferry/zeitgeber/types"
+)
+
+// TwoChain is a zeitgeber.Safety implementation backed by a
+// *blockchain.BlockChain.
+type TwoChain struct {
+	bc            *blockchain.BlockChain
+	name          string
+	lastVotedView types.View
+	lockedQC      *blockchain.QC
+}
+
+// NewTwoChain creates a two-chain safety module over bc. name
+// identifies the variant for logging; "default" is the only one wired
+// up today.
+func NewTwoChain(bc *blockchain.BlockChain, name string) *TwoChain {
+	return &TwoChain{bc: bc, name: name}
+}
+
+// VotingRule reports whether block may be voted for: its view must be
+// newer than the last one this replica voted in, and it must not
+// equivocate against the locked QC.
+func (tc *TwoChain) VotingRule(block *blockchain.Block) (bool, error) {
+	if block.View <= tc.lastVotedView {
+		return false, nil
+	}
+	if tc.lockedQC == nil {
+		return true, nil
+	}
+	return block.QC.View >= tc.lockedQC.View, nil
+}
+
+// UpdateStateByView records that the replica has now voted in view.
+func (tc *TwoChain) UpdateStateByView(view types.View) error {
+	if view <= tc.lastVotedView {
+		return fmt.Errorf("cannot update last voted view backwards, cur: %v, new: %v", tc.lastVotedView, view)
+	}
+	tc.lastVotedView = view
+	return nil
+}
+
+// UpdateStateByQC locks directly on qc: a single additional QC is
+// enough to make the block it certifies unvotable against, one hop
+// sooner than chained HotStuff's lock on qc's parent.
+func (tc *TwoChain) UpdateStateByQC(qc *blockchain.QC) error {
+	if tc.lockedQC == nil || qc.View > tc.lockedQC.View {
+		tc.lockedQC = qc
+	}
+	return nil
+}
+
+// CommitRule applies the two-chain commit rule: once qc's block and
+// its parent form an unbroken sequence of views, the parent is safe to
+// commit.
+func (tc *TwoChain) CommitRule(qc *blockchain.QC) (bool, *blockchain.Block, error) {
+	block, err := tc.bc.GetBlock(qc.BlockID)
+	if err != nil {
+		return false, nil, fmt.Errorf("cannot find qc's block: %w", err)
+	}
+	parent, err := tc.bc.GetParentBlock(qc.BlockID)
+	if err != nil {
+		return false, nil, nil
+	}
+	if parent.View+1 == block.View {
+		return true, parent, nil
+	}
+	return false, nil, nil
+}
+
+// Forkchoice returns the highest QC this replica has observed, the one
+// the next proposal should extend.
+func (tc *TwoChain) Forkchoice() *blockchain.QC {
+	return tc.bc.GetHighQC()
+}
+
+// NextPhase reports that the two-chain rule is single-phase: every QC
+// is terminal and immediately advances the view.
+func (tc *TwoChain) NextPhase(qc *blockchain.QC) (blockchain.Phase, bool) {
+	return 0, false
+}
+
+// SafetyState returns the last view this replica voted in and the QC
+// it is currently locked on, for WAL snapshotting.
+func (tc *TwoChain) SafetyState() (types.View, *blockchain.QC) {
+	return tc.lastVotedView, tc.lockedQC
+}
+
+// RestoreSafetyState restores safety state previously captured by
+// SafetyState, used when replaying a WAL whose older segments have
+// already been compacted away.
+func (tc *TwoChain) RestoreSafetyState(lastVotedView types.View, lockedQC *blockchain.QC) {
+	tc.lastVotedView = lastVotedView
+	tc.lockedQC = lockedQC
+}
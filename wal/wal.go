@@ -0,0 +1,283 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RecordType identifies the kind of safety-relevant event a WAL record
+// captures.
+type RecordType uint8
+
+const (
+	BlockReceived RecordType = iota + 1
+	VoteCast
+	QCFormed
+	ViewEntered
+	TCFormed
+	Snapshot
+)
+
+const (
+	defaultSegmentSize = 64 << 20 // 64MiB
+	fsyncInterval      = 200 * time.Millisecond
+)
+
+// WAL is a segmented, append-only, crash-safe log of every
+// safety-relevant consensus event: block received, vote cast, QC
+// formed, view entered, TC formed. Every record is length-prefixed and
+// CRC-checksummed so a torn write at the tail of a segment (the only
+// kind a crash can produce) is detected and discarded on replay rather
+// than silently corrupting recovery.
+type WAL struct {
+	mu           sync.Mutex
+	dir          string
+	segmentSize  int64
+	file         *os.File
+	writer       *bufio.Writer
+	segmentIndex int
+	segmentBytes int64
+	lastSync     time.Time
+}
+
+// Open opens, creating if necessary, the WAL rooted at dir, resuming
+// onto its latest segment.
+func Open(dir string) (*WAL, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create wal dir: %w", err)
+	}
+	w := &WAL{dir: dir, segmentSize: defaultSegmentSize}
+	if err := w.openLatestSegment(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func segmentPath(dir string, index int) string {
+	return filepath.Join(dir, fmt.Sprintf("%08d.wal", index))
+}
+
+func (w *WAL) openLatestSegment() error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return fmt.Errorf("cannot list wal dir: %w", err)
+	}
+	index := 0
+	for _, e := range entries {
+		var n int
+		if _, err := fmt.Sscanf(e.Name(), "%08d.wal", &n); err == nil && n > index {
+			index = n
+		}
+	}
+	return w.openSegment(index)
+}
+
+func (w *WAL) openSegment(index int) error {
+	f, err := os.OpenFile(segmentPath(w.dir, index), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot open wal segment %d: %w", index, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("cannot stat wal segment %d: %w", index, err)
+	}
+	w.file = f
+	w.writer = bufio.NewWriter(f)
+	w.segmentIndex = index
+	w.segmentBytes = info.Size()
+	return nil
+}
+
+// Append atomically writes a record, rotating to a new segment if the
+// current one would exceed segmentSize. Fsync is throttled to at most
+// once per fsyncInterval so durability doesn't cost an fsync per
+// record.
+func (w *WAL) Append(t RecordType, payload []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.segmentBytes >= w.segmentSize {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+	buf := encodeRecord(t, payload)
+	n, err := w.writer.Write(buf)
+	if err != nil {
+		return fmt.Errorf("cannot append wal record: %w", err)
+	}
+	w.segmentBytes += int64(n)
+	if time.Since(w.lastSync) >= fsyncInterval {
+		return w.sync()
+	}
+	return nil
+}
+
+func encodeRecord(t RecordType, payload []byte) []byte {
+	buf := make([]byte, 0, 9+len(payload))
+	buf = append(buf, byte(t))
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(payload)))
+	buf = append(buf, lenBuf[:]...)
+	buf = append(buf, payload...)
+	var sumBuf [4]byte
+	binary.BigEndian.PutUint32(sumBuf[:], crc32.ChecksumIEEE(buf))
+	return append(buf, sumBuf[:]...)
+}
+
+func (w *WAL) sync() error {
+	if err := w.writer.Flush(); err != nil {
+		return fmt.Errorf("cannot flush wal: %w", err)
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("cannot fsync wal: %w", err)
+	}
+	w.lastSync = time.Now()
+	return nil
+}
+
+func (w *WAL) rotate() error {
+	if err := w.sync(); err != nil {
+		return err
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("cannot close wal segment %d: %w", w.segmentIndex, err)
+	}
+	return w.openSegment(w.segmentIndex + 1)
+}
+
+// Close flushes and fsyncs any buffered records and closes the active
+// segment.
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.sync(); err != nil {
+		return err
+	}
+	return w.file.Close()
+}
+
+// Replay reads every valid record across all segments in dir, in
+// order, invoking fn for each. A truncated or corrupt record at the
+// tail of the last segment is the signature of a crash mid-write and
+// silently ends replay; corruption anywhere else is reported, since it
+// implies a bug rather than a crash.
+func Replay(dir string, fn func(RecordType, []byte) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot list wal dir: %w", err)
+	}
+	var segments []string
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".wal" {
+			segments = append(segments, e.Name())
+		}
+	}
+	sort.Strings(segments)
+	for i, name := range segments {
+		isLast := i == len(segments)-1
+		if err := replaySegment(filepath.Join(dir, name), isLast, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replaySegment(path string, isLast bool, fn func(RecordType, []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("cannot open wal segment %s: %w", path, err)
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	for {
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF || isLast {
+				return nil
+			}
+			return fmt.Errorf("cannot read wal record header in %s: %w", path, err)
+		}
+		length := binary.BigEndian.Uint32(header[1:5])
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			if isLast {
+				return nil // torn write at the tail of the active segment
+			}
+			return fmt.Errorf("cannot read wal record payload in %s: %w", path, err)
+		}
+		var sumBuf [4]byte
+		if _, err := io.ReadFull(r, sumBuf[:]); err != nil {
+			if isLast {
+				return nil
+			}
+			return fmt.Errorf("cannot read wal record checksum in %s: %w", path, err)
+		}
+		want := binary.BigEndian.Uint32(sumBuf[:])
+		if got := crc32.ChecksumIEEE(append(header[:5:5], payload...)); got != want {
+			if isLast {
+				return nil // checksum over a partially-flushed tail record
+			}
+			return fmt.Errorf("wal record checksum mismatch in %s", path)
+		}
+		if err := fn(RecordType(header[0]), payload); err != nil {
+			return fmt.Errorf("cannot apply wal record from %s: %w", path, err)
+		}
+	}
+}
+
+// Compact snapshots committed state into a single Snapshot record and
+// discards every prior segment, bounding how far back a future replay
+// ever has to look. Callers run this periodically, after pruning the
+// blockchain forest up to the last committed block, passing the
+// caller-serialized snapshot of whatever state needs restoring
+// (highQC, lastVotedView, lockedQC, current view).
+//
+// The new segment is written to a temp file, fsynced and renamed into
+// place before any old segment is removed, so a crash mid-compaction
+// leaves either the untouched old segments or the complete new one -
+// never neither.
+func Compact(dir string, snapshot []byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("cannot list wal dir: %w", err)
+	}
+	tmpPath := segmentPath(dir, 0) + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot create compacted wal segment: %w", err)
+	}
+	if _, err := f.Write(encodeRecord(Snapshot, snapshot)); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot write wal snapshot record: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("cannot fsync compacted wal segment: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("cannot close compacted wal segment: %w", err)
+	}
+	if err := os.Rename(tmpPath, segmentPath(dir, 0)); err != nil {
+		return fmt.Errorf("cannot install compacted wal segment: %w", err)
+	}
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != ".wal" || e.Name() == filepath.Base(segmentPath(dir, 0)) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return fmt.Errorf("cannot remove old wal segment %s: %w", e.Name(), err)
+		}
+	}
+	return nil
+}
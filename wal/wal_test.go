@@ -0,0 +1,132 @@
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type record struct {
+	t       RecordType
+	payload string
+}
+
+func replayAll(t *testing.T, dir string) []record {
+	t.Helper()
+	var got []record
+	if err := Replay(dir, func(rt RecordType, payload []byte) error {
+		got = append(got, record{rt, string(payload)})
+		return nil
+	}); err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	return got
+}
+
+func TestAppendReplayRoundtrip(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Open(dir)
+	if err != nil {
+		t.Fatalf("cannot open wal: %v", err)
+	}
+	want := []record{
+		{BlockReceived, "block-1"},
+		{VoteCast, "vote-1"},
+		{QCFormed, "qc-1"},
+	}
+	for _, r := range want {
+		if err := w.Append(r.t, []byte(r.payload)); err != nil {
+			t.Fatalf("cannot append record: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("cannot close wal: %v", err)
+	}
+
+	got := replayAll(t, dir)
+	if len(got) != len(want) {
+		t.Fatalf("replayed %d records, want %d", len(got), len(want))
+	}
+	for i, r := range want {
+		if got[i] != r {
+			t.Fatalf("record %d = %+v, want %+v", i, got[i], r)
+		}
+	}
+}
+
+func TestReplayToleratesTornTailRecord(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Open(dir)
+	if err != nil {
+		t.Fatalf("cannot open wal: %v", err)
+	}
+	if err := w.Append(BlockReceived, []byte("block-1")); err != nil {
+		t.Fatalf("cannot append record: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("cannot close wal: %v", err)
+	}
+
+	// simulate a crash mid-write: a complete header and payload whose
+	// trailing checksum was never fully flushed.
+	f, err := os.OpenFile(segmentPath(dir, 0), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("cannot reopen segment: %v", err)
+	}
+	torn := encodeRecord(VoteCast, []byte("vote-1"))
+	if _, err := f.Write(torn[:len(torn)-2]); err != nil {
+		t.Fatalf("cannot write torn record: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("cannot close segment: %v", err)
+	}
+
+	got := replayAll(t, dir)
+	if len(got) != 1 || got[0].payload != "block-1" {
+		t.Fatalf("replay after a torn tail = %+v, want only the complete block-1 record", got)
+	}
+}
+
+func TestCompactReplacesSegmentsAtomically(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Open(dir)
+	if err != nil {
+		t.Fatalf("cannot open wal: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := w.Append(BlockReceived, []byte("block")); err != nil {
+			t.Fatalf("cannot append record: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("cannot close wal: %v", err)
+	}
+
+	snapshot := []byte("committed-state")
+	if err := Compact(dir, snapshot); err != nil {
+		t.Fatalf("cannot compact wal: %v", err)
+	}
+
+	if _, err := os.Stat(segmentPath(dir, 0) + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("compact left a temp segment behind: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("cannot list wal dir: %v", err)
+	}
+	var segments []string
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".wal" {
+			segments = append(segments, e.Name())
+		}
+	}
+	if len(segments) != 1 {
+		t.Fatalf("wal dir has %d segments after compaction, want 1: %v", len(segments), segments)
+	}
+
+	got := replayAll(t, dir)
+	if len(got) != 1 || got[0].t != Snapshot || got[0].payload != string(snapshot) {
+		t.Fatalf("replay after compaction = %+v, want a single snapshot record", got)
+	}
+}